@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/pkg/errors"
+)
+
+// affectedTests prints the test packages or files that are affected by a set
+// of changed files.
+func affectedTests(args []string) {
+	flags := flag.NewFlagSet("affected-tests", flag.ExitOnError)
+	changed := flags.String("changed", "", "comma-separated changed files")
+	gitRange := flags.String("git", "", "use the files that changed in a git range instead, e.g. main..HEAD")
+	files := flags.Bool("files", false, "print the affected _test.go files instead of the packages")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go affected-tests <directory> --changed <files>")
+		fmt.Fprintln(flags.Output(), "       codesee-deps-go affected-tests <directory> --git <base>..<head>")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "The changed files are relative from the directory. The packages are printed")
+		fmt.Fprintln(flags.Output(), "as go test arguments, e.g. ./pkg/server, relative from the directory.")
+		flags.PrintDefaults()
+	}
+	// The flags are usually given after the directory, which the flag package
+	// doesn't allow, so the rest of the arguments are parsed again.
+	flags.Parse(args)
+	if flags.NArg() > 1 {
+		root := flags.Arg(0)
+		flags.Parse(flags.Args()[1:])
+		args = append([]string{root}, flags.Args()...)
+	} else {
+		args = flags.Args()
+	}
+
+	if len(args) != 1 || (*changed == "") == (*gitRange == "") {
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	root := args[0]
+	changedFiles := splitList(*changed)
+	if *gitRange != "" {
+		var err error
+		changedFiles, err = gitChangedFiles(root, *gitRange)
+		if err != nil {
+			errutils.Fatal(err)
+		}
+	}
+	for i, filename := range changedFiles {
+		changedFiles[i] = path.Clean(filepath.ToSlash(filename))
+	}
+
+	a, err := links.Analyze(root)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	tests := graph.FindAffectedTests(a, changedFiles)
+	list := tests.Files
+	if !*files {
+		list = make([]string, 0, len(tests.Packages))
+		for _, dir := range tests.Packages {
+			list = append(list, goPackageArg(dir))
+		}
+	}
+
+	if *format == "json" {
+		out, err := json.Marshal(list)
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	for _, item := range list {
+		fmt.Println(item)
+	}
+}
+
+// gitChangedFiles returns the files that changed in a git range, relative from
+// the directory. Files outside of the directory are left out.
+func gitChangedFiles(dir, rng string) ([]string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "diff", "--name-only", "--relative", rng)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "git diff %s: %s", rng, strings.TrimSpace(stderr.String()))
+	}
+
+	changed := []string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if line != "" {
+			changed = append(changed, line)
+		}
+	}
+	return changed, nil
+}
+
+// goPackageArg turns a directory into a relative package path for go test.
+func goPackageArg(dir string) string {
+	if dir == "." {
+		return dir
+	}
+	return "./" + dir
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+)
+
+// rankedNode is the centrality of a node along with the score it's ranked by.
+type rankedNode struct {
+	graph.Centrality
+	// Score is the centrality that the nodes are ranked by, relative to the
+	// most central node, and multiplied by the size relative to the largest
+	// node with --size.
+	Score float64 `json:"score"`
+}
+
+// centrality prints the most central files or packages, which are the ones
+// whose changes ripple the furthest.
+func centrality(args []string) {
+	flags := flag.NewFlagSet("centrality", flag.ExitOnError)
+	rev := addRevFlag(flags)
+	by := flags.String("by", "pagerank", "rank by pagerank or betweenness")
+	size := flags.Bool("size", false, "weigh the centrality by the number of lines, to find large central files")
+	top := flags.Int("top", 20, "the number of files to print, or 0 for all of them")
+	packages := flags.Bool("packages", false, "rank packages instead of files")
+	tests := flags.Bool("tests", false, "include the _test.go files")
+	focus := flags.String("focus", "", "only include files under this directory")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go centrality [flags] <directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		flags.Usage()
+		os.Exit(1)
+	}
+	var value func(c graph.Centrality) float64
+	switch *by {
+	case "pagerank":
+		value = func(c graph.Centrality) float64 { return c.PageRank }
+	case "betweenness":
+		value = func(c graph.Centrality) float64 { return c.Betweenness }
+	default:
+		fmt.Fprintf(os.Stderr, "unknown ranking %q\n", *by)
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	a, err := analyze(flags.Arg(0), *rev)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	g := graph.FromAnalysis(a).Focus(*focus)
+	if !*tests {
+		g = g.WithoutTests()
+	}
+	level := "file"
+	if *packages {
+		g = g.Packages()
+		level = "package"
+	}
+
+	ranked := rank(g.Centrality(), value, *size)
+	if *top > 0 && len(ranked) > *top {
+		ranked = ranked[:*top]
+	}
+
+	if *format == "json" {
+		out, err := json.Marshal(ranked)
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	width := len(level)
+	for _, r := range ranked {
+		if len(r.ID) > width {
+			width = len(r.ID)
+		}
+	}
+	fmt.Printf("%-*s  %8s  %11s  %6s  %5s\n", width, level, "pagerank", "betweenness", "lines", "score")
+	for _, r := range ranked {
+		fmt.Printf("%-*s  %8.4f  %11.4f  %6d  %5.2f\n", width, r.ID, r.PageRank, r.Betweenness, r.Lines, r.Score)
+	}
+}
+
+// rank scores the nodes by a centrality value and sorts them from the highest
+// score to the lowest. Both the value and the size are relative to their
+// maximum, so the scores are from 0 to 1.
+func rank(cs []graph.Centrality, value func(c graph.Centrality) float64, size bool) []rankedNode {
+	maxValue := 0.0
+	maxLines := 0
+	for _, c := range cs {
+		if value(c) > maxValue {
+			maxValue = value(c)
+		}
+		if c.Lines > maxLines {
+			maxLines = c.Lines
+		}
+	}
+
+	ranked := make([]rankedNode, 0, len(cs))
+	for _, c := range cs {
+		r := rankedNode{Centrality: c}
+		if maxValue > 0 {
+			r.Score = value(c) / maxValue
+		}
+		if size {
+			if maxLines > 0 {
+				r.Score *= float64(c.Lines) / float64(maxLines)
+			} else {
+				r.Score = 0
+			}
+		}
+		ranked = append(ranked, r)
+	}
+
+	// The nodes are sorted by ID to begin with, which breaks the ties.
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/Codesee-io/codesee-deps-go/pkg/output"
+	"github.com/Codesee-io/codesee-deps-go/pkg/rules"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultRulesFile is the name of the rules file that check looks for in
+	// the directory when --rules isn't given.
+	defaultRulesFile = ".codesee-deps-rules"
+	// defaultBaselineFile is the name of the baseline file that check looks
+	// for, and that --write-baseline writes to, in the directory when
+	// --baseline isn't given.
+	defaultBaselineFile = ".codesee-deps-baseline.json"
+)
+
+// check checks the links in a directory against the rules in a rules file, and
+// exits with a non-zero status if any of them are broken, except for the
+// violations in the baseline.
+func check(args []string) {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	rev := addRevFlag(flags)
+	rulesPath := flags.String("rules", "", "the rules file (default \"<directory>/"+defaultRulesFile+"\", which is optional with --format sarif)")
+	format := flags.String("format", "text", "output format: text, json or sarif")
+	baselinePath := flags.String("baseline", "", "only fail on violations that aren't in this baseline file, and remove the fixed ones from it (default \"<directory>/"+defaultBaselineFile+"\", which is optional)")
+	writeBaseline := flags.Bool("write-baseline", false, "write all the current violations to the baseline file and exit")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go check [flags] <directory>")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "Every line of the rules file is a rule like one of these:")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "  pkg/domain/** must not depend on pkg/http/**, pkg/db/**")
+		fmt.Fprintln(flags.Output(), "  pkg/http/** may only depend on pkg/domain/**")
+		fmt.Fprintln(flags.Output(), "  cmd/* may only be depended on by nothing")
+		fmt.Fprintln(flags.Output())
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "json" && *format != "sarif" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	root := flags.Arg(0)
+	defaultRules := *rulesPath == ""
+	if defaultRules {
+		*rulesPath = filepath.Join(root, defaultRulesFile)
+	}
+	rs, err := readRules(*rulesPath)
+	if errors.Is(err, fs.ErrNotExist) && defaultRules && *format == "sarif" {
+		// SARIF also reports the analyzer's diagnostics, which are still
+		// useful in projects without any rules.
+		rs, err = []rules.Rule{}, nil
+	}
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	fsys, err := revFS(root, *rev)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+	a, err := links.AnalyzeFS(root, fsys)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	violations := rules.Check(a, rs)
+
+	defaultBaseline := *baselinePath == ""
+	if defaultBaseline {
+		*baselinePath = filepath.Join(root, defaultBaselineFile)
+	}
+
+	if *writeBaseline {
+		err = writeBaselineFile(*baselinePath, rules.NewBaseline(violations))
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		fmt.Printf("wrote %d violations to %s\n", len(violations), *baselinePath)
+		return
+	}
+
+	known := 0
+	f, err := os.Open(*baselinePath)
+	useBaseline := !(errors.Is(err, fs.ErrNotExist) && defaultBaseline)
+	if useBaseline {
+		if err != nil {
+			errutils.Fatal(errors.WithStack(err))
+		}
+		baseline, err := rules.ReadBaseline(f)
+		f.Close()
+		if err != nil {
+			errutils.Fatal(errors.Wrap(err, *baselinePath))
+		}
+
+		var remaining rules.Baseline
+		violations, remaining = baseline.Apply(violations)
+		known = len(remaining.Violations)
+		if fixed := len(baseline.Violations) - known; fixed > 0 {
+			// The baseline only ever shrinks, so fixed violations can't be
+			// reintroduced without failing the check.
+			err = writeBaselineFile(*baselinePath, remaining)
+			if err != nil {
+				errutils.Fatal(err)
+			}
+			fmt.Fprintf(os.Stderr, "removed %d fixed violations from %s\n", fixed, *baselinePath)
+		}
+	}
+
+	switch *format {
+	case "json":
+		out, err := json.Marshal(violations)
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		fmt.Println(string(out))
+	case "sarif":
+		if fsys == nil {
+			fsys = os.DirFS(root)
+		}
+		// The diagnostics are included since the files with them are left out
+		// of the check, which would otherwise go unnoticed.
+		err = output.SARIF(os.Stdout, fsys, version, rs, violations, a.Diagnostics)
+		if err != nil {
+			errutils.Fatal(err)
+		}
+	default:
+		for _, d := range a.Diagnostics {
+			fmt.Fprintf(os.Stderr, "warning: %s:%d:%d: %s (skipped)\n", d.File, d.Line, d.Column, d.Message)
+		}
+		for _, v := range violations {
+			fmt.Printf("%s -> %s\n", v.From, v.To)
+			fmt.Printf("  breaks %s (%s:%d)\n", v.Rule, *rulesPath, v.Rule.Line)
+			fmt.Printf("  uses %s\n", describeReferences(v.References))
+		}
+		switch {
+		case useBaseline:
+			fmt.Printf("%d new violations, %d in the baseline\n", len(violations), known)
+		case len(violations) > 0:
+			fmt.Printf("%d violations\n", len(violations))
+		default:
+			fmt.Println("no violations")
+		}
+	}
+
+	if len(violations) > 0 {
+		os.Exit(1)
+	}
+}
+
+// writeBaselineFile writes a baseline to a file, replacing it if it exists.
+func writeBaselineFile(filename string, b rules.Baseline) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = b.Write(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return errors.WithStack(f.Close())
+}
+
+// readRules reads and parses a rules file.
+func readRules(filename string) ([]rules.Rule, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	rs, err := rules.Parse(f)
+	if err != nil {
+		return nil, errors.Wrap(err, filename)
+	}
+	return rs, nil
+}
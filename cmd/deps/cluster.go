@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+)
+
+// clusterReport is the JSON output of the cluster command.
+type clusterReport struct {
+	graph.Clustering
+	SplitPackages []graph.SplitPackage `json:"splitPackages"`
+}
+
+// cluster groups the files into clusters of tightly linked files, and prints
+// the clusters that don't line up with the packages.
+func cluster(args []string) {
+	flags := flag.NewFlagSet("cluster", flag.ExitOnError)
+	rev := addRevFlag(flags)
+	resolution := flags.Float64("resolution", 1, "higher values find more, smaller clusters")
+	all := flags.Bool("all", false, "print every cluster, including the ones that match a package")
+	tests := flags.Bool("tests", false, "include the _test.go files")
+	focus := flags.String("focus", "", "only include files under this directory")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go cluster [flags] <directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *resolution <= 0 {
+		fmt.Fprintln(os.Stderr, "the resolution needs to be greater than 0")
+		os.Exit(1)
+	}
+
+	a, err := analyze(flags.Arg(0), *rev)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	g := graph.FromAnalysis(a).Focus(*focus)
+	if !*tests {
+		g = g.WithoutTests()
+	}
+	clustering := g.Clusters(*resolution)
+	splits := clustering.SplitPackages()
+
+	if *format == "json" {
+		out, err := json.Marshal(clusterReport{Clustering: clustering, SplitPackages: splits})
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	split := map[string]bool{}
+	for _, s := range splits {
+		split[s.Package] = true
+	}
+
+	fmt.Printf("modularity %.2f\n", clustering.Modularity)
+	for i, c := range clustering.Clusters {
+		crosses := len(c.Packages) > 1
+		for _, p := range c.Packages {
+			crosses = crosses || split[p]
+		}
+		if !crosses && !*all {
+			continue
+		}
+
+		// The clusters are numbered from 1 in both sections, whether or not
+		// they're printed.
+		fmt.Println()
+		fmt.Printf("cluster %d: %d files in %s\n", i+1, len(c.Nodes), strings.Join(c.Packages, ", "))
+		for _, node := range c.Nodes {
+			fmt.Printf("  %s\n", node)
+		}
+	}
+
+	if len(splits) > 0 {
+		fmt.Println()
+		fmt.Println("packages split across clusters:")
+		for _, s := range splits {
+			numbers := make([]string, 0, len(s.Clusters))
+			for _, i := range s.Clusters {
+				numbers = append(numbers, strconv.Itoa(i+1))
+			}
+			fmt.Printf("  %s: clusters %s\n", s.Package, strings.Join(numbers, ", "))
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+)
+
+// cycles prints the groups of files or packages that depend on each other.
+func cycles(args []string) {
+	flags := flag.NewFlagSet("cycles", flag.ExitOnError)
+	rev := addRevFlag(flags)
+	packages := flags.Bool("packages", false, "find cycles between packages instead of files")
+	tests := flags.Bool("tests", false, "include the _test.go files")
+	focus := flags.String("focus", "", "only include files under this directory")
+	format := flags.String("format", "text", "output format: text or json")
+	suggest := flags.Bool("suggest", false, "suggest the cheapest links to cut to break every cycle")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go cycles [flags] <directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	a, err := analyze(flags.Arg(0), *rev)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	g := graph.FromAnalysis(a).Focus(*focus)
+	if !*tests {
+		g = g.WithoutTests()
+	}
+	// The file graph is kept to find the links behind the cuts, since the
+	// tests and the files outside the focus need to be left out of them too.
+	files := g
+	level := "file"
+	if *packages {
+		g = g.Packages()
+		level = "package"
+	}
+	cs := []suggestedCycle{}
+	for _, c := range g.Cycles() {
+		sc := suggestedCycle{Cycle: c}
+		if *suggest {
+			sc.Cuts = []cut{}
+			for _, e := range c.Cuts() {
+				sc.Cuts = append(sc.Cuts, cut{Edge: e, Links: fileEdges(a, files, e, *packages)})
+			}
+		}
+		cs = append(cs, sc)
+	}
+
+	if *format == "json" {
+		out, err := json.Marshal(cs)
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	if len(cs) == 0 {
+		fmt.Printf("no %s cycles\n", level)
+		return
+	}
+	for i, c := range cs {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s cycle %d: %d %ss, %d links, %d references\n", level, i+1, len(c.Nodes), level, len(c.Edges), c.Weight())
+		for _, e := range c.Edges {
+			fmt.Printf("  %s -> %s (%d)\n", e.From, e.To, e.Weight)
+		}
+		if !*suggest {
+			continue
+		}
+
+		fmt.Println("  suggested cuts:")
+		for _, cut := range c.Cuts {
+			if !*packages {
+				fmt.Printf("    %s -> %s (%d): %s\n", cut.From, cut.To, cut.Weight, describeReferences(cut.Links[0].References))
+				continue
+			}
+			fmt.Printf("    %s -> %s (%d)\n", cut.From, cut.To, cut.Weight)
+			for _, l := range cut.Links {
+				fmt.Printf("      %s -> %s: %s\n", l.From, l.To, describeReferences(l.References))
+			}
+		}
+	}
+}
+
+// suggestedCycle is a cycle along with the links that are suggested to be cut
+// to break it.
+type suggestedCycle struct {
+	graph.Cycle
+	Cuts []cut `json:"cuts,omitempty"`
+}
+
+// cut is an edge that's suggested to be cut, along with the links between the
+// files that make it up.
+type cut struct {
+	graph.Edge
+	Links []links.Edge `json:"links"`
+}
+
+// fileEdges returns the edges of the analysis that make up an edge of the
+// graph. When the graph is aggregated to packages, these are all the edges
+// between the files of both packages that are in the file graph the packages
+// were aggregated from.
+func fileEdges(a *links.Analysis, files *graph.Graph, e graph.Edge, packages bool) []links.Edge {
+	if !packages {
+		fe, _ := a.Edge(e.From, e.To)
+		return []links.Edge{fe}
+	}
+
+	edges := []links.Edge{}
+	for _, fe := range a.Edges {
+		if path.Dir(fe.From) != e.From || path.Dir(fe.To) != e.To {
+			continue
+		}
+		if files.Node(fe.From) == nil || files.Node(fe.To) == nil {
+			continue
+		}
+		edges = append(edges, fe)
+	}
+	return edges
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/Codesee-io/codesee-deps-go/pkg/output"
+	"github.com/pkg/errors"
+)
+
+// diff prints the differences between two outputs of the links command, or
+// between the links of two git revisions.
+func diff(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text, json or markdown")
+	threshold := flags.Int("threshold", 3, "minimum change in fan-in or fan-out for a file to be reported")
+	gitRange := flags.String("git", "", "compare the links of two git revisions, e.g. main..HEAD")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go diff [flags] <old.json> <new.json>")
+		fmt.Fprintln(flags.Output(), "       codesee-deps-go diff [flags] --git <base>..<head> [<directory>]")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "The files are the output of the links command in the json or ndjson format.")
+		fmt.Fprintln(flags.Output(), "With --git, the directory defaults to the current directory.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	var before, after []links.Link
+	var err error
+	if *gitRange != "" {
+		if flags.NArg() > 1 {
+			flags.Usage()
+			os.Exit(1)
+		}
+		root := "."
+		if flags.NArg() == 1 {
+			root = flags.Arg(0)
+		}
+		before, after, err = revisionLinks(root, *gitRange)
+	} else {
+		if flags.NArg() != 2 {
+			flags.Usage()
+			os.Exit(1)
+		}
+		before, err = readLinks(flags.Arg(0))
+		if err == nil {
+			after, err = readLinks(flags.Arg(1))
+		}
+	}
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	d := graph.Compare(before, after, *threshold)
+
+	switch *format {
+	case "text":
+		err = output.DiffText(os.Stdout, d)
+	case "markdown":
+		err = output.DiffMarkdown(os.Stdout, d)
+	case "json":
+		var out []byte
+		out, err = json.Marshal(d)
+		if err == nil {
+			fmt.Println(string(out))
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		flags.Usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		errutils.Fatal(err)
+	}
+}
+
+// revisionLinks returns the links in a directory at both ends of a git range.
+// Like in git, a missing end of the range means HEAD.
+func revisionLinks(root, gitRange string) ([]links.Link, []links.Link, error) {
+	revs := strings.SplitN(gitRange, "..", 2)
+	if len(revs) != 2 || strings.HasPrefix(revs[1], ".") {
+		return nil, nil, errors.Errorf("%q isn't a range like base..head", gitRange)
+	}
+
+	ls := [2][]links.Link{}
+	for i, rev := range revs {
+		if rev == "" {
+			rev = "HEAD"
+		}
+		a, err := analyze(root, rev)
+		if err != nil {
+			return nil, nil, err
+		}
+		ls[i] = a.Links()
+	}
+	return ls[0], ls[1], nil
+}
+
+// readLinks reads a file with either a JSON array of links or one JSON link
+// per line.
+func readLinks(filename string) ([]links.Link, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	ls := []links.Link{}
+	dec := json.NewDecoder(f)
+	for {
+		var value json.RawMessage
+		err := dec.Decode(&value)
+		if err == io.EOF {
+			return ls, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", filename)
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(string(value)), "[") {
+			var array []links.Link
+			err = json.Unmarshal(value, &array)
+			ls = append(ls, array...)
+		} else {
+			var l links.Link
+			err = json.Unmarshal(value, &l)
+			ls = append(ls, l)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", filename)
+		}
+	}
+}
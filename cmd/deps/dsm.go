@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/Codesee-io/codesee-deps-go/pkg/output"
+)
+
+// dsm writes a dependency structure matrix of the packages or directories in a
+// directory.
+func dsm(args []string) {
+	flags := flag.NewFlagSet("dsm", flag.ExitOnError)
+	rev := addRevFlag(flags)
+	depth := flags.Int("depth", 0, "aggregate to directories this many levels deep instead of packages")
+	focus := flags.String("focus", "", "only include files under this directory")
+	format := flags.String("format", "text", "output format: text or csv")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go dsm [flags] <directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	a, err := analyze(flags.Arg(0), *rev)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	g := graph.FromAnalysis(a).Focus(*focus)
+	if *depth > 0 {
+		g = g.Directories(*depth)
+	} else {
+		g = g.Packages()
+	}
+	m := g.Matrix()
+
+	switch *format {
+	case "text":
+		err = output.DSM(os.Stdout, m)
+	case "csv":
+		err = output.DSMCSV(os.Stdout, m)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		flags.Usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		errutils.Fatal(err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+)
+
+// impact prints every file that transitively depends on the given files.
+func impact(args []string) {
+	flags := flag.NewFlagSet("impact", flag.ExitOnError)
+	rev := addRevFlag(flags)
+	packages := flags.Bool("packages", false, "aggregate the dependents to packages")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go impact [flags] <directory> <files...>")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "The files are relative from the directory, and can also be directories to")
+		fmt.Fprintln(flags.Output(), "include every file in them.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	a, err := analyze(flags.Arg(0), *rev)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	changed := []string{}
+	for _, filename := range flags.Args()[1:] {
+		changed = append(changed, path.Clean(filepath.ToSlash(filename)))
+	}
+
+	dependents := graph.FromAnalysis(a).Dependents(changed)
+	if *packages {
+		dependents = graph.PackageDependents(dependents)
+	}
+
+	if *format == "json" {
+		out, err := json.Marshal(dependents)
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	for _, d := range dependents {
+		fmt.Printf("%d %s\n", d.Depth, d.ID)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/Codesee-io/codesee-deps-go/pkg/output"
+	"github.com/pkg/errors"
+)
+
+var (
+	// linkFormats are the formats that are a list of links.
+	linkFormats = []string{"json", "ndjson", "csv", "tsv", "sql"}
+	// graphFormats are the formats that are a document for another tool.
+	graphFormats = []string{"cypher", "lsif", "mermaid", "plantuml", "graphml", "gexf"}
+	// analysisFormats are the formats that are written straight from the
+	// analysis, so they can't be aggregated to packages or focused.
+	analysisFormats = []string{"json", "ndjson", "cypher", "lsif"}
+)
+
+// outputFlags are the flags shared by the commands that print the links in one
+// of the output formats.
+type outputFlags struct {
+	format     *string
+	packages   *bool
+	focus      *string
+	columns    *string
+	diagram    *string
+	groupDepth *int
+	external   *bool
+	rev        *string
+}
+
+// addOutputFlags adds the flags for the given formats to a flag set. The flags
+// that only apply to formats that aren't included are left out.
+func addOutputFlags(flags *flag.FlagSet, formats []string, defaultFormat string) *outputFlags {
+	o := &outputFlags{}
+	o.format = flags.String("format", defaultFormat, "output format: "+formatList(formats))
+	notFor := ""
+	if unsupported := intersect(formats, analysisFormats); len(unsupported) > 0 {
+		notFor = " (not for " + formatList(unsupported) + ")"
+	}
+	o.packages = flags.Bool("packages", false, "aggregate the links to packages"+notFor)
+	o.focus = flags.String("focus", "", "only include files under this directory"+notFor)
+	o.rev = addRevFlag(flags)
+
+	// Flags for formats that aren't part of the command still need to be
+	// pointing somewhere.
+	o.columns = new(string)
+	o.diagram = new(string)
+	*o.diagram = "component"
+	o.groupDepth = new(int)
+	*o.groupDepth = 1
+	o.external = new(bool)
+
+	if contains(formats, "csv") {
+		flags.StringVar(o.columns, "columns", "", "comma-separated optional columns for csv and tsv: weight, kind")
+	}
+	if contains(formats, "plantuml") {
+		flags.StringVar(o.diagram, "diagram", "component", "PlantUML diagram: component or package")
+		flags.IntVar(o.groupDepth, "group-depth", 1, "number of leading directories to group packages by (plantuml only)")
+		flags.BoolVar(o.external, "external", false, "include the external modules that packages import (plantuml only)")
+	}
+	return o
+}
+
+// root is the original codesee-deps-go [flags] <directory>, which accepts every
+// format along with the version flags.
+func root(args []string) {
+	flags := flag.NewFlagSet("codesee-deps-go", flag.ExitOnError)
+	var showVersion bool
+	flags.BoolVar(&showVersion, "v", false, "print the version and exit")
+	flags.BoolVar(&showVersion, "version", false, "print the version and exit")
+	formats := append(append([]string{}, linkFormats...), graphFormats...)
+	o := addOutputFlags(flags, formats, "json")
+	flags.Usage = func() {
+		usage(flags.Output())
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "Flags:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if showVersion {
+		printVersion()
+		os.Exit(0)
+	}
+
+	runOutput(flags, o, formats)
+}
+
+// linksCommand prints the links between the files in a directory.
+func linksCommand(args []string) {
+	flags := flag.NewFlagSet("links", flag.ExitOnError)
+	o := addOutputFlags(flags, linkFormats, "json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go links [flags] <directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	runOutput(flags, o, linkFormats)
+}
+
+// graphCommand prints the graph of a directory as a document for other tools.
+func graphCommand(args []string) {
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	o := addOutputFlags(flags, graphFormats, "graphml")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go graph [flags] <directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	runOutput(flags, o, graphFormats)
+}
+
+// runOutput analyzes the directory in the arguments of a command and prints it
+// in the selected format.
+func runOutput(flags *flag.FlagSet, o *outputFlags, formats []string) {
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	if !contains(formats, *o.format) {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *o.format)
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	if contains(analysisFormats, *o.format) {
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name == "packages" || f.Name == "focus" {
+				fmt.Fprintf(os.Stderr, "the %s format doesn't support --%s\n", *o.format, f.Name)
+				os.Exit(1)
+			}
+		})
+	}
+
+	root := flags.Arg(0)
+	fsys, err := revFS(root, *o.rev)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	if *o.format == "ndjson" {
+		// This is handled separately since it never holds all the links in
+		// memory at once.
+		w := bufio.NewWriter(os.Stdout)
+		enc := json.NewEncoder(w)
+		err := links.StreamLinksFS(root, fsys, func(l links.Link) error {
+			return enc.Encode(l)
+		})
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		err = w.Flush()
+		if err != nil {
+			errutils.Fatal(errors.WithStack(err))
+		}
+		return
+	}
+
+	a, err := links.AnalyzeFS(root, fsys)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	if *o.format == "json" {
+		out, err := json.Marshal(a.Links())
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	if *o.format == "lsif" {
+		if fsys != nil {
+			// The ranges are converted to UTF-16 by reading the files from
+			// disk, which would be the wrong version of them.
+			fmt.Fprintln(os.Stderr, "the lsif format doesn't support --rev")
+			os.Exit(1)
+		}
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			errutils.Fatal(errors.WithStack(err))
+		}
+		err = output.LSIF(os.Stdout, absRoot, version, a)
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		return
+	}
+
+	if *o.format == "cypher" {
+		// This works on the analysis rather than the graph since it needs to
+		// know which package and module every file is in.
+		err = output.Cypher(os.Stdout, a)
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		return
+	}
+
+	g := graph.FromAnalysis(a).Focus(*o.focus)
+	// PlantUML diagrams are always of packages, since a diagram of every file
+	// wouldn't be readable.
+	if *o.packages || *o.format == "plantuml" {
+		g = g.Packages()
+	}
+
+	switch *o.format {
+	case "csv":
+		err = output.CSV(os.Stdout, g, ',', splitList(*o.columns))
+	case "tsv":
+		err = output.CSV(os.Stdout, g, '\t', splitList(*o.columns))
+	case "sql":
+		err = output.SQL(os.Stdout, g)
+	case "mermaid":
+		err = output.Mermaid(os.Stdout, g)
+	case "plantuml":
+		externalEdges := []graph.Edge{}
+		if *o.external {
+			externalEdges = graph.ExternalEdges(a)
+		}
+		err = output.PlantUML(os.Stdout, g, externalEdges, output.PlantUMLOptions{
+			Diagram:    *o.diagram,
+			GroupDepth: *o.groupDepth,
+		})
+	case "graphml":
+		err = output.GraphML(os.Stdout, g)
+	case "gexf":
+		err = output.GEXF(os.Stdout, g)
+	}
+	if err != nil {
+		errutils.Fatal(err)
+	}
+}
+
+// formatList formats a list of formats for a flag description, e.g. "json, csv
+// or tsv".
+func formatList(formats []string) string {
+	if len(formats) == 1 {
+		return formats[0]
+	}
+	return strings.Join(formats[:len(formats)-1], ", ") + " or " + formats[len(formats)-1]
+}
+
+// intersect returns the values of a list that are also in another, in the order
+// of the first list.
+func intersect(list, other []string) []string {
+	values := []string{}
+	for _, value := range list {
+		if contains(other, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"strings"
 	"time"
 
-	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/gitfs"
 	"github.com/Codesee-io/codesee-deps-go/pkg/links"
 )
 
@@ -16,26 +19,102 @@ var (
 	date    = time.Now().Format(time.RFC3339)
 )
 
+// command is a subcommand, e.g. codesee-deps-go links <directory>. Every
+// command parses its own flags from the arguments after its name.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
+
+// commands returns every subcommand in the order they're listed in the usage.
+// It's a function rather than a variable since the commands print the usage
+// themselves, which would otherwise be an initialization cycle.
+func commands() []command {
+	return []command{
+		{name: "links", summary: "print the links between files (the default)", run: linksCommand},
+		{name: "graph", summary: "print the file or package graph as a document for other tools", run: graphCommand},
+		{name: "check", summary: "check the links against architecture rules", run: check},
+		{name: "why", summary: "explain why one file depends on another", run: why},
+		{name: "impact", summary: "list the files that depend on the given files", run: impact},
+		{name: "affected-tests", summary: "list the tests that are affected by changed files", run: affectedTests},
+		{name: "cycles", summary: "list the cycles between files or packages", run: cycles},
+		{name: "orphans", summary: "list the files that nothing depends on", run: orphans},
+		{name: "metrics", summary: "print the coupling and stability metrics of packages or files", run: metrics},
+		{name: "centrality", summary: "list the most central files, whose changes ripple the furthest", run: centrality},
+		{name: "cluster", summary: "group tightly linked files to suggest package boundaries", run: cluster},
+		{name: "diff", summary: "compare the links from two runs", run: diff},
+		{name: "dsm", summary: "print a dependency structure matrix", run: dsm},
+		{name: "report", summary: "write an interactive HTML report", run: report},
+		{name: "tags", summary: "write a ctags or etags file", run: tags},
+		{name: "version", summary: "print the version", run: versionCommand},
+	}
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: codesee-deps-go <directory>")
-		os.Exit(1)
+	if len(os.Args) > 1 {
+		for _, c := range commands() {
+			if os.Args[1] == c.name {
+				c.run(os.Args[2:])
+				return
+			}
+		}
+		if os.Args[1] == "help" {
+			usage(os.Stdout)
+			return
+		}
 	}
 
-	if os.Args[1] == "-v" || os.Args[1] == "--version" {
-		fmt.Printf("codesee-deps-go version %s\ncommit: %s\nbuilt at: %s\n", version, commit, date)
-		os.Exit(0)
+	// Anything else is the original codesee-deps-go [flags] <directory>, which
+	// the codesee CLI depends on, so it accepts the flags and formats of both
+	// the links and graph commands.
+	root(os.Args[1:])
+}
+
+// usage prints the usage of the root command along with every subcommand.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: codesee-deps-go [flags] <directory>")
+	fmt.Fprintln(w, "       codesee-deps-go <command> [flags] <directory>")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-15s %s\n", c.name, c.summary)
 	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run codesee-deps-go <command> --help for the flags of a command.")
+}
 
-	root := os.Args[1]
-	l, err := links.DetermineLinks(root)
-	if err != nil {
-		errutils.Fatal(err)
+// addRevFlag adds the --rev flag to a command that analyzes a directory.
+func addRevFlag(flags *flag.FlagSet) *string {
+	return flags.String("rev", "", "analyze the directory at this git revision instead of the files on disk")
+}
+
+// revFS returns the files of a directory at a git revision, or nil to read
+// them from disk if there's no revision.
+func revFS(root, rev string) (fs.FS, error) {
+	if rev == "" {
+		return nil, nil
 	}
+	return gitfs.Open(root, rev)
+}
 
-	out, err := json.Marshal(l)
+// analyze analyzes a directory, either from disk or at a git revision.
+func analyze(root, rev string) (*links.Analysis, error) {
+	fsys, err := revFS(root, rev)
 	if err != nil {
-		errutils.Fatal(err)
+		return nil, err
+	}
+	return links.AnalyzeFS(root, fsys)
+}
+
+// splitList splits a comma-separated flag value, ignoring empty items.
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
 	}
-	fmt.Println(string(out))
+	return list
 }
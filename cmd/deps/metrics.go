@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+)
+
+// metricsSorts are the values that the metrics can be sorted by. Everything
+// except the name is sorted from high to low.
+var metricsSorts = map[string]func(m graph.Metrics) float64{
+	"fan-in":       func(m graph.Metrics) float64 { return float64(m.FanIn) },
+	"fan-out":      func(m graph.Metrics) float64 { return float64(m.FanOut) },
+	"instability":  func(m graph.Metrics) float64 { return m.Instability },
+	"abstractness": func(m graph.Metrics) float64 { return m.Abstractness },
+	"distance":     func(m graph.Metrics) float64 { return m.Distance },
+}
+
+// metrics prints the coupling and stability metrics of every package, or the
+// fan-in and fan-out of every file.
+func metrics(args []string) {
+	flags := flag.NewFlagSet("metrics", flag.ExitOnError)
+	rev := addRevFlag(flags)
+	files := flags.Bool("files", false, "print the fan-in and fan-out of every file instead of the package metrics")
+	tests := flags.Bool("tests", false, "include the _test.go files")
+	focus := flags.String("focus", "", "only include files under this directory")
+	sortBy := flags.String("sort", "name", "sort by name, fan-in, fan-out, instability, abstractness or distance")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go metrics [flags] <directory>")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "For packages, Ca and Ce are the number of packages that depend on it and that")
+		fmt.Fprintln(flags.Output(), "it depends on, I is the instability Ce/(Ca+Ce), A is the abstractness (the")
+		fmt.Fprintln(flags.Output(), "share of interfaces among the exported types), and D is the distance from the")
+		fmt.Fprintln(flags.Output(), "main sequence |A+I-1|.")
+		fmt.Fprintln(flags.Output())
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		flags.Usage()
+		os.Exit(1)
+	}
+	key, ok := metricsSorts[*sortBy]
+	if !ok && *sortBy != "name" {
+		fmt.Fprintf(os.Stderr, "unknown sort %q\n", *sortBy)
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	a, err := analyze(flags.Arg(0), *rev)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	g := graph.FromAnalysis(a).Focus(*focus)
+	if !*tests {
+		g = g.WithoutTests()
+	}
+	if !*files {
+		g = g.Packages()
+	}
+	ms := g.Metrics()
+	if key != nil {
+		// The metrics are already sorted by name, which breaks the ties.
+		sort.SliceStable(ms, func(i, j int) bool {
+			return key(ms[i]) > key(ms[j])
+		})
+	}
+
+	if *format == "json" {
+		out, err := json.Marshal(ms)
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	width := len("package")
+	for _, m := range ms {
+		if len(m.ID) > width {
+			width = len(m.ID)
+		}
+	}
+	if *files {
+		fmt.Printf("%-*s  %6s  %7s\n", width, "file", "fan-in", "fan-out")
+		for _, m := range ms {
+			fmt.Printf("%-*s  %6d  %7d\n", width, m.ID, m.FanIn, m.FanOut)
+		}
+		return
+	}
+	fmt.Printf("%-*s  %4s  %4s  %4s  %4s  %4s\n", width, "package", "Ca", "Ce", "I", "A", "D")
+	for _, m := range ms {
+		fmt.Printf("%-*s  %4d  %4d  %4.2f  %4.2f  %4.2f\n", width, m.ID, m.FanIn, m.FanOut, m.Instability, m.Abstractness, m.Distance)
+	}
+}
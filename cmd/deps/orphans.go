@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+)
+
+// orphans prints the files that nothing depends on, or that can't be reached
+// from any entrypoint.
+func orphans(args []string) {
+	flags := flag.NewFlagSet("orphans", flag.ExitOnError)
+	rev := addRevFlag(flags)
+	unreachable := flags.Bool("unreachable", false, "list the files that can't be reached from a main package, a test or an exported API")
+	all := flags.Bool("all", false, "include the files in main packages and the test files, which never have incoming links")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go orphans [flags] <directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	a, err := analyze(flags.Arg(0), *rev)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	g := graph.FromAnalysis(a)
+	list := []string{}
+	if *unreachable {
+		list = g.Unreachable(a.Entrypoints())
+	} else {
+		for _, id := range g.Orphans() {
+			if *all || !isCommandOrTest(a, id) {
+				list = append(list, id)
+			}
+		}
+	}
+
+	if *format == "json" {
+		out, err := json.Marshal(list)
+		if err != nil {
+			errutils.Fatal(err)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	for _, id := range list {
+		fmt.Println(id)
+	}
+}
+
+// isCommandOrTest returns whether a file is in a main package or is a test
+// file.
+func isCommandOrTest(a *links.Analysis, filename string) bool {
+	for _, f := range a.Files {
+		if f.Name == filename {
+			return f.Test || f.PackageName == "main"
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/Codesee-io/codesee-deps-go/pkg/output"
+	"github.com/pkg/errors"
+)
+
+// report writes an interactive HTML report for a directory.
+func report(args []string) {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	htmlPath := flags.String("html", "", "write the HTML report to this file")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go report --html <file> <directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *htmlPath == "" || flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	root := flags.Arg(0)
+	a, err := links.Analyze(root)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		errutils.Fatal(errors.WithStack(err))
+	}
+
+	f, err := os.Create(*htmlPath)
+	if err != nil {
+		errutils.Fatal(errors.WithStack(err))
+	}
+
+	err = output.Report(f, filepath.Base(absRoot), graph.FromAnalysis(a))
+	if err != nil {
+		f.Close()
+		errutils.Fatal(err)
+	}
+	err = f.Close()
+	if err != nil {
+		errutils.Fatal(errors.WithStack(err))
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/Codesee-io/codesee-deps-go/pkg/output"
+	"github.com/pkg/errors"
+)
+
+// tags writes a ctags or etags file with all the symbols in a directory.
+func tags(args []string) {
+	flags := flag.NewFlagSet("tags", flag.ExitOnError)
+	etags := flags.Bool("etags", false, "write an Emacs TAGS file instead of a ctags file")
+	outputPath := flags.String("output", "", "write the tags to this file, or - for stdout (default \"tags\", or \"TAGS\" with --etags)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go tags [--etags] [--output <file>] <directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	if *outputPath == "" {
+		*outputPath = "tags"
+		if *etags {
+			*outputPath = "TAGS"
+		}
+	}
+
+	root := flags.Arg(0)
+	a, err := links.Analyze(root)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		errutils.Fatal(errors.WithStack(err))
+	}
+
+	// The filenames in a tags file are relative from the tags file itself, so
+	// we need the path from there to the root directory.
+	w := os.Stdout
+	tagsDir := "."
+	if *outputPath != "-" {
+		f, err := os.Create(*outputPath)
+		if err != nil {
+			errutils.Fatal(errors.WithStack(err))
+		}
+		defer f.Close()
+		w = f
+		tagsDir = filepath.Dir(*outputPath)
+	}
+	absTagsDir, err := filepath.Abs(tagsDir)
+	if err != nil {
+		errutils.Fatal(errors.WithStack(err))
+	}
+	prefix, err := filepath.Rel(absTagsDir, absRoot)
+	if err != nil {
+		errutils.Fatal(errors.WithStack(err))
+	}
+
+	if *etags {
+		err = output.ETags(w, a, absRoot, prefix)
+	} else {
+		err = output.CTags(w, a, prefix, version)
+	}
+	if err != nil {
+		errutils.Fatal(err)
+	}
+}
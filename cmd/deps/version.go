@@ -0,0 +1,22 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// versionCommand prints the version, commit and build date.
+func versionCommand(args []string) {
+	flags := flag.NewFlagSet("version", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go version")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	printVersion()
+}
+
+func printVersion() {
+	fmt.Printf("codesee-deps-go version %s\ncommit: %s\nbuilt at: %s\n", version, commit, date)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/errutils"
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+)
+
+// why prints the shortest chain of links from one file to another, along with
+// the identifiers that cause every link.
+func why(args []string) {
+	flags := flag.NewFlagSet("why", flag.ExitOnError)
+	rev := addRevFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: codesee-deps-go why [flags] <directory> <from> <to>")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "The from and to files are relative from the directory, and either one can")
+		fmt.Fprintln(flags.Output(), "also be a directory to start or end at any of the files in it.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 3 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	a, err := analyze(flags.Arg(0), *rev)
+	if err != nil {
+		errutils.Fatal(err)
+	}
+
+	from := path.Clean(filepath.ToSlash(flags.Arg(1)))
+	to := path.Clean(filepath.ToSlash(flags.Arg(2)))
+	p := graph.FromAnalysis(a).ShortestPath(from, to)
+	if p == nil {
+		fmt.Fprintf(os.Stderr, "%s doesn't depend on %s\n", from, to)
+		os.Exit(1)
+	}
+
+	for i, filename := range p {
+		fmt.Println(filename)
+		if i == len(p)-1 {
+			break
+		}
+		e, _ := a.Edge(filename, p[i+1])
+		fmt.Printf("  uses %s\n", describeReferences(e.References))
+	}
+}
+
+// describeReferences lists every identifier that's referenced along with the
+// line it's first used on, e.g. "New (line 15), Options (line 16)".
+func describeReferences(refs []links.Reference) string {
+	seen := map[string]bool{}
+	descriptions := []string{}
+	for _, ref := range refs {
+		if seen[ref.Identifier] {
+			continue
+		}
+		seen[ref.Identifier] = true
+		descriptions = append(descriptions, fmt.Sprintf("%s (line %d)", ref.Identifier, ref.Line))
+	}
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,213 @@
+// Package gitfs reads the files of a git revision into memory, so they can be
+// analyzed without checking the revision out.
+package gitfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Open reads the files of a revision (a commit, branch, tag or anything else
+// that git rev-parse understands) from the git repository that dir is in. Only
+// the files under dir are read, and the root of the returned file system is
+// dir. Symbolic links and submodules are left out.
+func Open(dir, rev string) (fs.FS, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "archive", "--format=tar", rev)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "git archive %s: %s", rev, strings.TrimSpace(stderr.String()))
+	}
+
+	return fromTar(&stdout)
+}
+
+// fromTar reads the regular files in a tar archive into memory.
+func fromTar(r io.Reader) (*memFS, error) {
+	m := &memFS{
+		files: map[string]*memFile{},
+		dirs:  map[string]map[string]*memFile{},
+	}
+	m.addDir(".", time.Time{})
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		name := strings.TrimSuffix(header.Name, "/")
+		if !fs.ValidPath(name) {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			m.addDir(name, header.ModTime)
+		case tar.TypeReg:
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			m.add(&memFile{name: name, content: content, modTime: header.ModTime, mode: fs.FileMode(header.Mode).Perm()})
+		}
+	}
+
+	return m, nil
+}
+
+// memFS is a read-only file system that's fully in memory.
+type memFS struct {
+	// files is every file and directory by its path.
+	files map[string]*memFile
+	// dirs is the entries of every directory by their name.
+	dirs map[string]map[string]*memFile
+}
+
+// add adds a file along with every directory it's in.
+func (m *memFS) add(f *memFile) {
+	m.addDir(path.Dir(f.name), f.modTime)
+	m.files[f.name] = f
+	m.dirs[path.Dir(f.name)][path.Base(f.name)] = f
+}
+
+func (m *memFS) addDir(name string, modTime time.Time) {
+	if _, ok := m.dirs[name]; ok {
+		return
+	}
+	m.dirs[name] = map[string]*memFile{}
+	dir := &memFile{name: name, dir: true, modTime: modTime, mode: fs.ModeDir | 0755}
+	if name != "." {
+		m.add(dir)
+	} else {
+		m.files[name] = dir
+	}
+}
+
+func (m *memFS) Open(name string) (fs.File, error) {
+	f, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if f.dir {
+		entries, _ := m.ReadDir(name)
+		return &openDir{memFile: f, entries: entries}, nil
+	}
+	return &openFile{memFile: f, Reader: bytes.NewReader(f.content)}, nil
+}
+
+func (m *memFS) ReadFile(name string) ([]byte, error) {
+	f, err := m.lookup("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if f.dir {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	return append([]byte{}, f.content...), nil
+}
+
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if _, err := m.lookup("readdir", name); err != nil {
+		return nil, err
+	}
+	children, ok := m.dirs[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, f := range children {
+		entries = append(entries, fs.FileInfoToDirEntry(f))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (m *memFS) Stat(name string) (fs.FileInfo, error) {
+	return m.lookup("stat", name)
+}
+
+func (m *memFS) lookup(op, name string) (*memFile, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	f, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return f, nil
+}
+
+// memFile is a file or directory in a memFS. It's also its own fs.FileInfo.
+type memFile struct {
+	name    string
+	content []byte
+	dir     bool
+	modTime time.Time
+	mode    fs.FileMode
+}
+
+func (f *memFile) Name() string       { return path.Base(f.name) }
+func (f *memFile) Size() int64        { return int64(len(f.content)) }
+func (f *memFile) Mode() fs.FileMode  { return f.mode }
+func (f *memFile) ModTime() time.Time { return f.modTime }
+func (f *memFile) IsDir() bool        { return f.dir }
+func (f *memFile) Sys() interface{}   { return nil }
+
+// openFile is a regular file that was opened for reading.
+type openFile struct {
+	*memFile
+	*bytes.Reader
+}
+
+func (f *openFile) Stat() (fs.FileInfo, error) { return f.memFile, nil }
+func (f *openFile) Close() error               { return nil }
+
+// openDir is a directory that was opened for reading its entries.
+type openDir struct {
+	*memFile
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *openDir) Stat() (fs.FileInfo, error) { return d.memFile, nil }
+func (d *openDir) Close() error               { return nil }
+
+func (d *openDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *openDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return remaining[:n], nil
+}
@@ -0,0 +1,83 @@
+package gitfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromTar(t *testing.T) {
+	t.Run("reads the regular files and their directories", func(tt *testing.T) {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, h := range []tar.Header{
+			{Name: "cmd/", Typeflag: tar.TypeDir, Mode: 0755},
+			{Name: "cmd/api/main.go", Typeflag: tar.TypeReg, Mode: 0644, Size: 12},
+			{Name: "go.mod", Typeflag: tar.TypeReg, Mode: 0644, Size: 5},
+			{Name: "link.go", Typeflag: tar.TypeSymlink, Linkname: "go.mod"},
+		} {
+			require.NoError(tt, tw.WriteHeader(&h))
+			if h.Size > 0 {
+				_, err := tw.Write(bytes.Repeat([]byte("x"), int(h.Size)))
+				require.NoError(tt, err)
+			}
+		}
+		require.NoError(tt, tw.Close())
+
+		fsys, err := fromTar(&buf)
+		require.NoError(tt, err)
+
+		require.NoError(tt, fstest.TestFS(fsys, "cmd/api/main.go", "go.mod"))
+		_, err = fs.Stat(fsys, "link.go")
+		assert.ErrorIs(tt, err, fs.ErrNotExist)
+	})
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("reads a revision of a repository", func(tt *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			tt.Skip("git isn't installed")
+		}
+
+		dir := tt.TempDir()
+		git := func(args ...string) {
+			cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+			require.NoError(tt, err, string(out))
+		}
+		write := func(name, content string) {
+			require.NoError(tt, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		}
+
+		git("init", "-q")
+		write("go.mod", "module example.com/app\n")
+		git("add", "-A")
+		git("commit", "-q", "-m", "first")
+		write("main.go", "package main\n")
+		git("add", "-A")
+		git("commit", "-q", "-m", "second")
+
+		fsys, err := Open(dir, "HEAD~1")
+		require.NoError(tt, err)
+
+		content, err := fs.ReadFile(fsys, "go.mod")
+		require.NoError(tt, err)
+		assert.Equal(tt, "module example.com/app\n", string(content))
+		_, err = fs.Stat(fsys, "main.go")
+		assert.ErrorIs(tt, err, fs.ErrNotExist)
+	})
+
+	t.Run("returns an error for an unknown revision", func(tt *testing.T) {
+		_, err := Open(".", "does-not-exist")
+		assert.Error(tt, err)
+	})
+}
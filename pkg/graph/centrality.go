@@ -0,0 +1,173 @@
+package graph
+
+import "math"
+
+const (
+	// damping is the probability that the random walk of PageRank follows a
+	// link rather than jumping to a random node.
+	damping = 0.85
+	// pageRankIterations is the most iterations that PageRank does before it
+	// gives up on converging.
+	pageRankIterations = 100
+	// pageRankTolerance is the total change in rank below which PageRank has
+	// converged.
+	pageRankTolerance = 1e-9
+)
+
+// Centrality is how central a node is in the graph, which is a measure of how
+// far changes to it can ripple.
+type Centrality struct {
+	ID string `json:"id"`
+	// PageRank is the share of the time that a random walk along the links
+	// spends at the node. Since links point from a file to the files it
+	// depends on, nodes that a lot of central nodes depend on rank highest.
+	// The ranks of all the nodes add up to 1.
+	PageRank float64 `json:"pageRank"`
+	// Betweenness is the share of the shortest paths between every other pair
+	// of nodes that go through the node, from 0 to 1. Nodes with a high
+	// betweenness are the bridges between parts of the graph.
+	Betweenness float64 `json:"betweenness"`
+	// Lines is the number of lines in the node, which is copied from the node
+	// so that the centrality can be weighed against the size.
+	Lines int `json:"lines"`
+}
+
+// Centrality returns the centrality of every node, in the same order as the
+// nodes. The edge weights aren't taken into account, so a link counts the same
+// no matter how many references it has.
+func (g *Graph) Centrality() []Centrality {
+	adjacent := g.indexAdjacency()
+	pageRank := pageRank(adjacent)
+	betweenness := betweenness(adjacent)
+
+	centrality := make([]Centrality, 0, len(g.Nodes))
+	for i, n := range g.Nodes {
+		centrality = append(centrality, Centrality{
+			ID:          n.ID,
+			PageRank:    pageRank[i],
+			Betweenness: betweenness[i],
+			Lines:       n.Lines,
+		})
+	}
+	return centrality
+}
+
+// indexAdjacency is like adjacency, but the nodes are their index in g.Nodes,
+// which is quicker for algorithms that visit every node many times.
+func (g *Graph) indexAdjacency() [][]int {
+	index := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		index[n.ID] = i
+	}
+	adjacent := make([][]int, len(g.Nodes))
+	for _, e := range g.Edges {
+		from := index[e.From]
+		adjacent[from] = append(adjacent[from], index[e.To])
+	}
+	return adjacent
+}
+
+// pageRank computes the PageRank of every node with power iteration. The rank
+// of nodes without any outgoing links is spread evenly over all the nodes.
+func pageRank(adjacent [][]int) []float64 {
+	n := len(adjacent)
+	if n == 0 {
+		return []float64{}
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	for iteration := 0; iteration < pageRankIterations; iteration++ {
+		dangling := 0.0
+		for i, next := range adjacent {
+			if len(next) == 0 {
+				dangling += rank[i]
+			}
+		}
+
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		nextRank := make([]float64, n)
+		for i := range nextRank {
+			nextRank[i] = base
+		}
+		for i, next := range adjacent {
+			for _, j := range next {
+				nextRank[j] += damping * rank[i] / float64(len(next))
+			}
+		}
+
+		change := 0.0
+		for i := range rank {
+			change += math.Abs(nextRank[i] - rank[i])
+		}
+		rank = nextRank
+		if change < pageRankTolerance {
+			break
+		}
+	}
+	return rank
+}
+
+// betweenness computes the betweenness centrality of every node with Brandes'
+// algorithm, normalized by the number of pairs of other nodes.
+func betweenness(adjacent [][]int) []float64 {
+	n := len(adjacent)
+	centrality := make([]float64, n)
+
+	// These are reused for every source to avoid allocating them n times.
+	paths := make([]float64, n)
+	distance := make([]int, n)
+	dependency := make([]float64, n)
+	predecessors := make([][]int, n)
+	for source := range adjacent {
+		for i := range adjacent {
+			paths[i] = 0
+			distance[i] = -1
+			dependency[i] = 0
+			predecessors[i] = predecessors[i][:0]
+		}
+		paths[source] = 1
+		distance[source] = 0
+
+		// A breadth-first search counts the number of shortest paths from the
+		// source to every node, and keeps the order the nodes were reached in.
+		order := []int{}
+		queue := []int{source}
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			order = append(order, node)
+			for _, next := range adjacent[node] {
+				if distance[next] < 0 {
+					distance[next] = distance[node] + 1
+					queue = append(queue, next)
+				}
+				if distance[next] == distance[node]+1 {
+					paths[next] += paths[node]
+					predecessors[next] = append(predecessors[next], node)
+				}
+			}
+		}
+
+		// Going back from the furthest nodes, every node passes its share of
+		// the shortest paths on to the nodes before it.
+		for i := len(order) - 1; i >= 0; i-- {
+			node := order[i]
+			for _, previous := range predecessors[node] {
+				dependency[previous] += paths[previous] / paths[node] * (1 + dependency[node])
+			}
+			if node != source {
+				centrality[node] += dependency[node]
+			}
+		}
+	}
+
+	if n > 2 {
+		for i := range centrality {
+			centrality[i] /= float64((n - 1) * (n - 2))
+		}
+	}
+	return centrality
+}
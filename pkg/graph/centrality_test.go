@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph_Centrality(t *testing.T) {
+	t.Run("ranks the files that everything depends on highest", func(tt *testing.T) {
+		g := build([]Node{
+			{ID: "a.go", Lines: 10},
+			{ID: "b.go", Lines: 20},
+			{ID: "c.go", Lines: 30},
+			{ID: "core.go", Lines: 40},
+		}, []Edge{
+			{From: "a.go", To: "core.go", Weight: 1},
+			{From: "b.go", To: "core.go", Weight: 1},
+			{From: "c.go", To: "core.go", Weight: 1},
+		})
+
+		centrality := g.Centrality()
+		require.Len(tt, centrality, 4)
+
+		total := 0.0
+		for _, c := range centrality {
+			total += c.PageRank
+		}
+		assert.InDelta(tt, 1, total, 1e-6)
+
+		assert.Equal(tt, "core.go", centrality[3].ID)
+		assert.Equal(tt, 40, centrality[3].Lines)
+		for _, c := range centrality[:3] {
+			assert.Greater(tt, centrality[3].PageRank, c.PageRank)
+			assert.InDelta(tt, centrality[0].PageRank, c.PageRank, 1e-9)
+		}
+	})
+
+	t.Run("gives the bridges between files a betweenness", func(tt *testing.T) {
+		g := build(nil, []Edge{
+			{From: "a.go", To: "b.go", Weight: 1},
+			{From: "a.go", To: "c.go", Weight: 1},
+			{From: "b.go", To: "d.go", Weight: 1},
+			{From: "c.go", To: "d.go", Weight: 1},
+		})
+
+		centrality := g.Centrality()
+
+		// b.go and c.go each have half of the shortest paths from a.go to
+		// d.go, out of the 3 * 2 pairs of other nodes.
+		assert.InDelta(tt, 0, centrality[0].Betweenness, 1e-9)
+		assert.InDelta(tt, 1.0/12, centrality[1].Betweenness, 1e-9)
+		assert.InDelta(tt, 1.0/12, centrality[2].Betweenness, 1e-9)
+		assert.InDelta(tt, 0, centrality[3].Betweenness, 1e-9)
+	})
+
+	t.Run("counts every path through a chain", func(tt *testing.T) {
+		g := build(nil, []Edge{
+			{From: "a.go", To: "b.go", Weight: 1},
+			{From: "b.go", To: "c.go", Weight: 1},
+		})
+
+		centrality := g.Centrality()
+
+		assert.InDelta(tt, 0.5, centrality[1].Betweenness, 1e-9)
+	})
+
+	t.Run("handles an empty graph", func(tt *testing.T) {
+		assert.Empty(tt, build(nil, nil).Centrality())
+	})
+}
@@ -0,0 +1,267 @@
+package graph
+
+import (
+	"path"
+	"sort"
+)
+
+// Clustering is the result of community detection on a graph.
+type Clustering struct {
+	// Modularity is how much more the nodes are linked within their clusters
+	// than they would be if the links were random, from -0.5 to 1. Anything
+	// above 0.3 or so means that the clusters are real.
+	Modularity float64 `json:"modularity"`
+	// Clusters is sorted by size, largest first.
+	Clusters []Cluster `json:"clusters"`
+}
+
+// Cluster is a group of nodes that are more tightly linked to each other than
+// to the rest of the graph.
+type Cluster struct {
+	// Nodes is sorted by ID.
+	Nodes []string `json:"nodes"`
+	// Packages is every package directory that the nodes are in, sorted.
+	Packages []string `json:"packages"`
+}
+
+// SplitPackage is a package whose files are in more than one cluster.
+type SplitPackage struct {
+	Package string `json:"package"`
+	// Clusters is the index of every cluster that the package's files are in.
+	Clusters []int `json:"clusters"`
+}
+
+// Clusters finds the clusters of a file graph with the Louvain method, which
+// groups the nodes so that the modularity is as high as it can find. The
+// direction of the edges is ignored, and their weights are added up. A higher
+// resolution finds more, smaller clusters, and 1 is the standard modularity.
+// Nodes without any edges aren't in any cluster. The result is deterministic
+// for the same graph.
+func (g *Graph) Clusters(resolution float64) Clustering {
+	index := map[string]int{}
+	ids := []string{}
+	for _, e := range g.Edges {
+		for _, id := range []string{e.From, e.To} {
+			if _, ok := index[id]; !ok {
+				index[id] = -1
+			}
+		}
+	}
+	// The nodes are numbered in the order of g.Nodes so that the result
+	// doesn't depend on the order of the edges.
+	for _, n := range g.Nodes {
+		if _, ok := index[n.ID]; ok {
+			index[n.ID] = len(ids)
+			ids = append(ids, n.ID)
+		}
+	}
+
+	weights := make([]map[int]float64, len(ids))
+	for i := range weights {
+		weights[i] = map[int]float64{}
+	}
+	for _, e := range g.Edges {
+		from, to := index[e.From], index[e.To]
+		weights[from][to] += float64(e.Weight)
+		weights[to][from] += float64(e.Weight)
+	}
+
+	// membership is the community of every node in the original graph, and
+	// it's updated after every level of aggregation.
+	membership := make([]int, len(ids))
+	for i := range membership {
+		membership[i] = i
+	}
+	for {
+		communities, moved := louvainLevel(weights, resolution)
+		if !moved {
+			break
+		}
+		for i := range membership {
+			membership[i] = communities[membership[i]]
+		}
+		weights = aggregateCommunities(weights, communities)
+	}
+
+	return Clustering{
+		Modularity: modularity(weights, resolution),
+		Clusters:   clusterList(ids, membership),
+	}
+}
+
+// louvainLevel moves every node to the neighboring community that increases
+// the modularity the most, until no move increases it anymore. It returns the
+// community of every node, numbered from 0, and whether any node was moved.
+func louvainLevel(weights []map[int]float64, resolution float64) ([]int, bool) {
+	n := len(weights)
+	community := make([]int, n)
+	// degree is the total weight of every node's edges, and total is the
+	// total degree of the nodes in every community.
+	degree := make([]float64, n)
+	total := make([]float64, n)
+	twiceWeight := 0.0
+	for i, edges := range weights {
+		community[i] = i
+		for _, w := range edges {
+			degree[i] += w
+		}
+		total[i] = degree[i]
+		twiceWeight += degree[i]
+	}
+	if twiceWeight == 0 {
+		return community, false
+	}
+
+	moved := false
+	for improved := true; improved; {
+		improved = false
+		for i, edges := range weights {
+			// The weight from the node to each neighboring community, in a
+			// sorted order so that ties are always broken the same way.
+			toCommunity := map[int]float64{}
+			for j, w := range edges {
+				if j != i {
+					toCommunity[community[j]] += w
+				}
+			}
+			neighbors := make([]int, 0, len(toCommunity))
+			for c := range toCommunity {
+				neighbors = append(neighbors, c)
+			}
+			sort.Ints(neighbors)
+
+			// Taking the node out of its community first means that staying
+			// is just another move, and any other community has to be
+			// strictly better.
+			current := community[i]
+			total[current] -= degree[i]
+			best := current
+			bestGain := toCommunity[current] - resolution*total[current]*degree[i]/twiceWeight
+			for _, c := range neighbors {
+				gain := toCommunity[c] - resolution*total[c]*degree[i]/twiceWeight
+				if gain > bestGain+1e-12 {
+					best = c
+					bestGain = gain
+				}
+			}
+			total[best] += degree[i]
+
+			if best != current {
+				community[i] = best
+				improved = true
+				moved = true
+			}
+		}
+	}
+
+	// Renumber the communities so they're numbered from 0 without gaps.
+	numbers := map[int]int{}
+	for i, c := range community {
+		if _, ok := numbers[c]; !ok {
+			numbers[c] = len(numbers)
+		}
+		community[i] = numbers[c]
+	}
+	return community, moved
+}
+
+// aggregateCommunities builds the graph where every community is a node, and
+// the weights between them are the sum of the weights between their nodes.
+// The weights within a community become the weight of a self-loop.
+func aggregateCommunities(weights []map[int]float64, community []int) []map[int]float64 {
+	count := 0
+	for _, c := range community {
+		if c+1 > count {
+			count = c + 1
+		}
+	}
+	aggregated := make([]map[int]float64, count)
+	for i := range aggregated {
+		aggregated[i] = map[int]float64{}
+	}
+	for i, edges := range weights {
+		for j, w := range edges {
+			aggregated[community[i]][community[j]] += w
+		}
+	}
+	return aggregated
+}
+
+// modularity computes the modularity of a graph where every node is a
+// community, as it is after the last aggregation.
+func modularity(weights []map[int]float64, resolution float64) float64 {
+	twiceWeight := 0.0
+	for _, edges := range weights {
+		for _, w := range edges {
+			twiceWeight += w
+		}
+	}
+	if twiceWeight == 0 {
+		return 0
+	}
+
+	q := 0.0
+	for i, edges := range weights {
+		degree := 0.0
+		for _, w := range edges {
+			degree += w
+		}
+		q += edges[i]/twiceWeight - resolution*(degree/twiceWeight)*(degree/twiceWeight)
+	}
+	return q
+}
+
+// clusterList groups the nodes by their community, largest first, and then by
+// their first node.
+func clusterList(ids []string, membership []int) []Cluster {
+	byCommunity := map[int][]string{}
+	for i, c := range membership {
+		byCommunity[c] = append(byCommunity[c], ids[i])
+	}
+
+	clusters := make([]Cluster, 0, len(byCommunity))
+	for _, nodes := range byCommunity {
+		sort.Strings(nodes)
+		packages := []string{}
+		seen := map[string]bool{}
+		for _, node := range nodes {
+			dir := path.Dir(node)
+			if !seen[dir] {
+				seen[dir] = true
+				packages = append(packages, dir)
+			}
+		}
+		sort.Strings(packages)
+		clusters = append(clusters, Cluster{Nodes: nodes, Packages: packages})
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Nodes) != len(clusters[j].Nodes) {
+			return len(clusters[i].Nodes) > len(clusters[j].Nodes)
+		}
+		return clusters[i].Nodes[0] < clusters[j].Nodes[0]
+	})
+	return clusters
+}
+
+// SplitPackages returns every package whose files are in more than one
+// cluster, sorted by package. These are candidates for being split up.
+func (c Clustering) SplitPackages() []SplitPackage {
+	clusters := map[string][]int{}
+	for i, cluster := range c.Clusters {
+		for _, p := range cluster.Packages {
+			clusters[p] = append(clusters[p], i)
+		}
+	}
+
+	splits := []SplitPackage{}
+	for p, cs := range clusters {
+		if len(cs) > 1 {
+			splits = append(splits, SplitPackage{Package: p, Clusters: cs})
+		}
+	}
+	sort.Slice(splits, func(i, j int) bool {
+		return splits[i].Package < splits[j].Package
+	})
+	return splits
+}
@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph_Clusters(t *testing.T) {
+	// Two groups of tightly linked files with a single link between them. The
+	// first group is split over two packages, and pkg/b is split between both
+	// groups.
+	g := build([]Node{{ID: "pkg/lonely/lonely.go"}}, []Edge{
+		{From: "pkg/a/a1.go", To: "pkg/a/a2.go", Weight: 1},
+		{From: "pkg/a/a2.go", To: "pkg/b/b1.go", Weight: 1},
+		{From: "pkg/b/b1.go", To: "pkg/a/a1.go", Weight: 1},
+		{From: "pkg/b/b1.go", To: "pkg/c/c1.go", Weight: 1},
+		{From: "pkg/c/c1.go", To: "pkg/c/c2.go", Weight: 1},
+		{From: "pkg/c/c2.go", To: "pkg/b/b2.go", Weight: 1},
+		{From: "pkg/b/b2.go", To: "pkg/c/c1.go", Weight: 1},
+	})
+
+	t.Run("finds the tightly linked groups", func(tt *testing.T) {
+		clustering := g.Clusters(1)
+
+		require.Len(tt, clustering.Clusters, 2)
+		assert.Equal(tt, Cluster{
+			Nodes:    []string{"pkg/a/a1.go", "pkg/a/a2.go", "pkg/b/b1.go"},
+			Packages: []string{"pkg/a", "pkg/b"},
+		}, clustering.Clusters[0])
+		assert.Equal(tt, Cluster{
+			Nodes:    []string{"pkg/b/b2.go", "pkg/c/c1.go", "pkg/c/c2.go"},
+			Packages: []string{"pkg/b", "pkg/c"},
+		}, clustering.Clusters[1])
+		assert.InDelta(tt, 2*(6.0/14-0.25), clustering.Modularity, 1e-9)
+	})
+
+	t.Run("lists the packages that are split between clusters", func(tt *testing.T) {
+		assert.Equal(tt, []SplitPackage{
+			{Package: "pkg/b", Clusters: []int{0, 1}},
+		}, g.Clusters(1).SplitPackages())
+	})
+
+	t.Run("merges everything with a low resolution", func(tt *testing.T) {
+		clustering := g.Clusters(0.01)
+
+		require.Len(tt, clustering.Clusters, 1)
+		assert.Len(tt, clustering.Clusters[0].Nodes, 6)
+	})
+
+	t.Run("prefers the heavier links", func(tt *testing.T) {
+		g := build(nil, []Edge{
+			{From: "a.go", To: "b.go", Weight: 10},
+			{From: "b.go", To: "c.go", Weight: 1},
+			{From: "c.go", To: "d.go", Weight: 10},
+		})
+
+		clustering := g.Clusters(1)
+
+		assert.Equal(tt, []Cluster{
+			{Nodes: []string{"a.go", "b.go"}, Packages: []string{"."}},
+			{Nodes: []string{"c.go", "d.go"}, Packages: []string{"."}},
+		}, clustering.Clusters)
+	})
+
+	t.Run("handles a graph without edges", func(tt *testing.T) {
+		clustering := build([]Node{{ID: "a.go"}}, nil).Clusters(1)
+
+		assert.Empty(tt, clustering.Clusters)
+		assert.Zero(tt, clustering.Modularity)
+	})
+}
@@ -0,0 +1,128 @@
+package graph
+
+import "sort"
+
+// StronglyConnectedComponents returns the strongly connected components of the
+// graph, which are the groups of nodes that can all reach each other. Every
+// node is in exactly one component, so nodes that aren't part of a cycle are
+// in a component by themselves. The components are in reverse topological
+// order, so a component only ever depends on the components before it. The
+// nodes within a component are sorted.
+func (g *Graph) StronglyConnectedComponents() [][]string {
+	// This is Tarjan's algorithm. Every node gets an index in the order it's
+	// visited, and a low link, which is the smallest index that's reachable
+	// from it while it's still on the stack. A node whose low link is its own
+	// index is the root of a component, and everything above it on the stack
+	// is in that component.
+	adjacent := g.adjacency()
+	index := map[string]int{}
+	lowLink := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	components := [][]string{}
+
+	var visit func(node string)
+	visit = func(node string) {
+		index[node] = len(index)
+		lowLink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, next := range adjacent[node] {
+			if _, ok := index[next]; !ok {
+				visit(next)
+				if lowLink[next] < lowLink[node] {
+					lowLink[node] = lowLink[next]
+				}
+			} else if onStack[next] && index[next] < lowLink[node] {
+				lowLink[node] = index[next]
+			}
+		}
+
+		if lowLink[node] != index[node] {
+			return
+		}
+
+		component := []string{}
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == node {
+				break
+			}
+		}
+		sort.Strings(component)
+		components = append(components, component)
+	}
+
+	for _, n := range g.Nodes {
+		if _, ok := index[n.ID]; !ok {
+			visit(n.ID)
+		}
+	}
+
+	return components
+}
+
+// adjacency returns a mapping from every node to the nodes it has an edge to.
+// Since the edges are sorted, so are the adjacent nodes.
+func (g *Graph) adjacency() map[string][]string {
+	adjacent := map[string][]string{}
+	for _, e := range g.Edges {
+		adjacent[e.From] = append(adjacent[e.From], e.To)
+	}
+	return adjacent
+}
+
+// Cycle is a group of nodes that all depend on each other, along with the
+// edges between them that form the cycle.
+type Cycle struct {
+	// Nodes is the sorted list of node IDs in the cycle.
+	Nodes []string `json:"nodes"`
+	// Edges is the sorted list of edges between the nodes in the cycle.
+	Edges []Edge `json:"edges"`
+}
+
+// Weight is the total weight of the edges in the cycle.
+func (c Cycle) Weight() int {
+	return totalWeight(c.Edges)
+}
+
+// Cycles returns every strongly connected component with more than one node,
+// which are the groups of nodes that are tangled together through cycles. On
+// a file graph, cycles are a design smell. On a package graph, they would be
+// compile errors, unless they're caused by tests in an external _test package,
+// so test files should usually be removed first. The cycles are sorted by
+// their number of nodes, with the largest ones first.
+func (g *Graph) Cycles() []Cycle {
+	component := map[string]int{}
+	cycles := []Cycle{}
+	for _, nodes := range g.StronglyConnectedComponents() {
+		if len(nodes) < 2 {
+			continue
+		}
+		for _, id := range nodes {
+			component[id] = len(cycles)
+		}
+		cycles = append(cycles, Cycle{Nodes: nodes, Edges: []Edge{}})
+	}
+
+	for _, e := range g.Edges {
+		from, fromOK := component[e.From]
+		to, toOK := component[e.To]
+		if fromOK && toOK && from == to {
+			cycles[from].Edges = append(cycles[from].Edges, e)
+		}
+	}
+
+	sort.SliceStable(cycles, func(i, j int) bool {
+		if len(cycles[i].Nodes) == len(cycles[j].Nodes) {
+			return cycles[i].Nodes[0] < cycles[j].Nodes[0]
+		}
+		return len(cycles[i].Nodes) > len(cycles[j].Nodes)
+	})
+
+	return cycles
+}
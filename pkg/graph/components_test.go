@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_StronglyConnectedComponents(t *testing.T) {
+	t.Run("groups the nodes that are in a cycle", func(tt *testing.T) {
+		g := New([]links.Link{
+			{From: "a.go", To: "b.go"},
+			{From: "b.go", To: "c.go"},
+			{From: "c.go", To: "a.go"},
+			{From: "c.go", To: "d.go"},
+			{From: "e.go", To: "a.go"},
+		})
+
+		assert.Equal(tt, [][]string{
+			{"d.go"},
+			{"a.go", "b.go", "c.go"},
+			{"e.go"},
+		}, g.StronglyConnectedComponents())
+	})
+
+	t.Run("puts every node in its own component without cycles", func(tt *testing.T) {
+		g := New(testLinks)
+
+		components := g.StronglyConnectedComponents()
+		assert.Len(tt, components, len(g.Nodes))
+	})
+}
+
+func TestGraph_Cycles(t *testing.T) {
+	t.Run("returns the cycles with their edges", func(tt *testing.T) {
+		g := New([]links.Link{
+			{From: "a.go", To: "b.go"},
+			{From: "b.go", To: "a.go"},
+			{From: "b.go", To: "e.go"},
+			{From: "c.go", To: "d.go"},
+			{From: "d.go", To: "e.go"},
+			{From: "e.go", To: "c.go"},
+		})
+
+		assert.Equal(tt, []Cycle{
+			{
+				Nodes: []string{"c.go", "d.go", "e.go"},
+				Edges: []Edge{
+					{From: "c.go", To: "d.go", Weight: 1},
+					{From: "d.go", To: "e.go", Weight: 1},
+					{From: "e.go", To: "c.go", Weight: 1},
+				},
+			},
+			{
+				Nodes: []string{"a.go", "b.go"},
+				Edges: []Edge{
+					{From: "a.go", To: "b.go", Weight: 1},
+					{From: "b.go", To: "a.go", Weight: 1},
+				},
+			},
+		}, g.Cycles())
+	})
+
+	t.Run("returns an empty list without cycles", func(tt *testing.T) {
+		assert.Empty(tt, New(testLinks).Cycles())
+	})
+}
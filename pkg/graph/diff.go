@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+)
+
+// Diff is what changed between two lists of links.
+type Diff struct {
+	// Added is every link that's only in the new links, sorted.
+	Added []links.Link `json:"added"`
+	// Removed is every link that's only in the old links, sorted.
+	Removed []links.Link `json:"removed"`
+	// NewCycles is every cycle between packages in the new links that has an
+	// edge that wasn't part of any cycle before, so cycles that grew are
+	// included as well. Files within a package referring to each other is
+	// normal, so cycles between files aren't included.
+	NewCycles []Cycle `json:"newCycles"`
+	// FanChanges is every file whose fan-in or fan-out changed by at least the
+	// threshold, sorted by filename.
+	FanChanges []FanChange `json:"fanChanges"`
+}
+
+// FanChange is how the number of links to and from a file changed.
+type FanChange struct {
+	ID string `json:"id"`
+	// FanIn is the number of files that depend on this one, before and after.
+	FanIn [2]int `json:"fanIn"`
+	// FanOut is the number of files that this one depends on, before and
+	// after.
+	FanOut [2]int `json:"fanOut"`
+}
+
+// Compare returns the differences between the links before and after a change.
+// Fan-in and fan-out changes are only included when either one changed by at
+// least threshold links.
+func Compare(before, after []links.Link, threshold int) Diff {
+	oldGraph := New(before)
+	newGraph := New(after)
+	diff := Diff{
+		Added:      edgeDifference(newGraph.Edges, oldGraph.Edges),
+		Removed:    edgeDifference(oldGraph.Edges, newGraph.Edges),
+		NewCycles:  []Cycle{},
+		FanChanges: []FanChange{},
+	}
+
+	oldCycleEdges := map[[2]string]bool{}
+	for _, c := range packageGraph(before).Cycles() {
+		for _, e := range c.Edges {
+			oldCycleEdges[[2]string{e.From, e.To}] = true
+		}
+	}
+	for _, c := range packageGraph(after).Cycles() {
+		for _, e := range c.Edges {
+			if !oldCycleEdges[[2]string{e.From, e.To}] {
+				diff.NewCycles = append(diff.NewCycles, c)
+				break
+			}
+		}
+	}
+
+	changes := map[string]*FanChange{}
+	change := func(id string) *FanChange {
+		if _, ok := changes[id]; !ok {
+			changes[id] = &FanChange{ID: id}
+		}
+		return changes[id]
+	}
+	for i, g := range []*Graph{oldGraph, newGraph} {
+		for _, e := range g.Edges {
+			change(e.From).FanOut[i]++
+			change(e.To).FanIn[i]++
+		}
+	}
+	for _, c := range changes {
+		if abs(c.FanIn[1]-c.FanIn[0]) >= threshold || abs(c.FanOut[1]-c.FanOut[0]) >= threshold {
+			diff.FanChanges = append(diff.FanChanges, *c)
+		}
+	}
+	sort.Slice(diff.FanChanges, func(i, j int) bool {
+		return diff.FanChanges[i].ID < diff.FanChanges[j].ID
+	})
+
+	return diff
+}
+
+// packageGraph builds the package graph that cycles are looked for in. Links
+// to and from _test.go files are left out, since tests in an external _test
+// package would otherwise show up as package cycles even though they compile.
+func packageGraph(ls []links.Link) *Graph {
+	withoutTests := []links.Link{}
+	for _, l := range ls {
+		if !strings.HasSuffix(l.From, "_test.go") && !strings.HasSuffix(l.To, "_test.go") {
+			withoutTests = append(withoutTests, l)
+		}
+	}
+	return New(withoutTests).Packages()
+}
+
+// edgeDifference returns the links for the edges in a that aren't in b. Both
+// lists of edges need to be sorted.
+func edgeDifference(a, b []Edge) []links.Link {
+	in := map[[2]string]bool{}
+	for _, e := range b {
+		in[[2]string{e.From, e.To}] = true
+	}
+
+	difference := []links.Link{}
+	for _, e := range a {
+		if !in[[2]string{e.From, e.To}] {
+			difference = append(difference, links.Link{From: e.From, To: e.To})
+		}
+	}
+	return difference
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
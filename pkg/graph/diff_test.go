@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	old := []links.Link{
+		{From: "a/a.go", To: "b/b.go"},
+		{From: "b/b.go", To: "a/a.go"},
+		{From: "c/c.go", To: "d/d.go"},
+	}
+
+	t.Run("returns the added and removed links and new cycles", func(tt *testing.T) {
+		diff := Compare(old, []links.Link{
+			{From: "a/a.go", To: "b/b.go"},
+			{From: "b/b.go", To: "a/a.go"},
+			{From: "d/d.go", To: "e/e.go"},
+			{From: "e/e.go", To: "d/d.go"},
+		}, 10)
+
+		assert.Equal(tt, Diff{
+			Added:   []links.Link{{From: "d/d.go", To: "e/e.go"}, {From: "e/e.go", To: "d/d.go"}},
+			Removed: []links.Link{{From: "c/c.go", To: "d/d.go"}},
+			NewCycles: []Cycle{
+				{
+					Nodes: []string{"d", "e"},
+					Edges: []Edge{
+						{From: "d", To: "e", Weight: 1},
+						{From: "e", To: "d", Weight: 1},
+					},
+				},
+			},
+			FanChanges: []FanChange{},
+		}, diff)
+	})
+
+	t.Run("includes a cycle that grew", func(tt *testing.T) {
+		diff := Compare(old, []links.Link{
+			{From: "a/a.go", To: "b/b.go"},
+			{From: "b/b.go", To: "c/c.go"},
+			{From: "c/c.go", To: "a/a.go"},
+		}, 10)
+
+		assert.Len(tt, diff.NewCycles, 1)
+		assert.Equal(tt, []string{"a", "b", "c"}, diff.NewCycles[0].Nodes)
+	})
+
+	t.Run("ignores cycles between the files of a package", func(tt *testing.T) {
+		diff := Compare(old, append([]links.Link{
+			{From: "c/c.go", To: "c/util.go"},
+			{From: "c/util.go", To: "c/c.go"},
+		}, old...), 10)
+
+		assert.Len(tt, diff.Added, 2)
+		assert.Empty(tt, diff.NewCycles)
+	})
+
+	t.Run("ignores cycles through test files", func(tt *testing.T) {
+		diff := Compare(old, append([]links.Link{
+			{From: "d/d_test.go", To: "c/c.go"},
+		}, old...), 10)
+
+		assert.Empty(tt, diff.NewCycles)
+	})
+
+	t.Run("returns the fan-in and fan-out changes over the threshold", func(tt *testing.T) {
+		diff := Compare(old, []links.Link{
+			{From: "a/a.go", To: "b/b.go"},
+			{From: "b/b.go", To: "a/a.go"},
+			{From: "c/c.go", To: "a/a.go"},
+			{From: "d/d.go", To: "a/a.go"},
+		}, 2)
+
+		assert.Equal(tt, []FanChange{
+			{ID: "a/a.go", FanIn: [2]int{1, 3}, FanOut: [2]int{1, 1}},
+		}, diff.FanChanges)
+	})
+}
@@ -0,0 +1,232 @@
+package graph
+
+import "sort"
+
+// Cuts suggests a set of edges to remove to break the cycle, cheapest first.
+// It's an approximation of the minimum feedback arc set, where the cost of an
+// edge is its weight, so it prefers cutting edges with fewer references. The
+// set is minimal: putting back any of the edges would bring a cycle back.
+func (c Cycle) Cuts() []Edge {
+	// Finding the minimum set is NP-hard, so two heuristics are tried and the
+	// cheapest result wins. Ordering the nodes is good at finding the edges
+	// that go against the general direction of the dependencies, while cutting
+	// the lightest edges is good at small cycles.
+	cuts := minimalCuts(c.Edges, orderingCuts(c))
+	if lightest := minimalCuts(c.Edges, lightestCuts(c)); totalWeight(lightest) < totalWeight(cuts) {
+		cuts = lightest
+	}
+
+	sort.Slice(cuts, func(i, j int) bool {
+		if cuts[i].Weight != cuts[j].Weight {
+			return cuts[i].Weight < cuts[j].Weight
+		}
+		if cuts[i].From != cuts[j].From {
+			return cuts[i].From < cuts[j].From
+		}
+		return cuts[i].To < cuts[j].To
+	})
+	return cuts
+}
+
+// orderingCuts puts the nodes in an order that has as little weight as
+// possible going backwards, using the greedy heuristic from Eades, Lin and
+// Smyth, and returns the edges that go backwards. Sinks go at the end and
+// sources at the start since none of their edges can go backwards. When there
+// are neither, the node with the most outgoing weight compared to its incoming
+// weight goes at the start.
+func orderingCuts(c Cycle) []Edge {
+	remaining := map[string]bool{}
+	for _, id := range c.Nodes {
+		remaining[id] = true
+	}
+
+	// The incoming and outgoing weight of every node only counts the edges to
+	// and from the remaining nodes, so it's updated as nodes are removed.
+	in := map[string]int{}
+	out := map[string]int{}
+	outgoing := map[string][]Edge{}
+	incoming := map[string][]Edge{}
+	for _, e := range c.Edges {
+		if e.From == e.To {
+			continue
+		}
+		out[e.From] += e.Weight
+		in[e.To] += e.Weight
+		outgoing[e.From] = append(outgoing[e.From], e)
+		incoming[e.To] = append(incoming[e.To], e)
+	}
+
+	// queue is the nodes that might have become a sink or a source.
+	queue := append([]string{}, c.Nodes...)
+	remove := func(id string) {
+		delete(remaining, id)
+		for _, e := range outgoing[id] {
+			if remaining[e.To] {
+				in[e.To] -= e.Weight
+				queue = append(queue, e.To)
+			}
+		}
+		for _, e := range incoming[id] {
+			if remaining[e.From] {
+				out[e.From] -= e.Weight
+				queue = append(queue, e.From)
+			}
+		}
+	}
+
+	start := []string{}
+	// end is built backwards, since sinks are added in front of the ones that
+	// were found before them.
+	end := []string{}
+	for len(remaining) > 0 {
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			if !remaining[id] {
+				continue
+			}
+			if out[id] == 0 {
+				end = append(end, id)
+				remove(id)
+			} else if in[id] == 0 {
+				start = append(start, id)
+				remove(id)
+			}
+		}
+
+		best := ""
+		for _, id := range c.Nodes {
+			if remaining[id] && (best == "" || out[id]-in[id] > out[best]-in[best]) {
+				best = id
+			}
+		}
+		if best != "" {
+			start = append(start, best)
+			remove(best)
+		}
+	}
+
+	position := map[string]int{}
+	for i, id := range start {
+		position[id] = i
+	}
+	for i, id := range end {
+		position[id] = len(start) + len(end) - 1 - i
+	}
+
+	cuts := []Edge{}
+	for _, e := range c.Edges {
+		if position[e.From] >= position[e.To] {
+			cuts = append(cuts, e)
+		}
+	}
+	return cuts
+}
+
+// maxLightestCutsEdges is the most edges a cycle can have for lightestCuts to
+// be tried, since it needs to look for cycles again after every cut. It's
+// meant for small cycles, where the ordering heuristic can miss the cheapest
+// cut.
+const maxLightestCutsEdges = 200
+
+// lightestCuts keeps cutting the lightest edge that's still part of a cycle
+// until there aren't any cycles left. It returns nil for cycles with more than
+// maxLightestCutsEdges edges.
+func lightestCuts(c Cycle) []Edge {
+	if len(c.Edges) > maxLightestCutsEdges {
+		return nil
+	}
+
+	cuts := []Edge{}
+	// Cutting an edge only affects the cycle it's in, so only that cycle
+	// needs to be looked at again.
+	cycles := []Cycle{c}
+	for len(cycles) > 0 {
+		cycle := cycles[len(cycles)-1]
+		cycles = cycles[:len(cycles)-1]
+
+		lightest := 0
+		for i, e := range cycle.Edges {
+			if e.Weight < cycle.Edges[lightest].Weight {
+				lightest = i
+			}
+		}
+		cuts = append(cuts, cycle.Edges[lightest])
+
+		kept := make([]Edge, 0, len(cycle.Edges)-1)
+		kept = append(kept, cycle.Edges[:lightest]...)
+		kept = append(kept, cycle.Edges[lightest+1:]...)
+		cycles = append(cycles, build(nil, kept).Cycles()...)
+	}
+	return cuts
+}
+
+// minimalCuts puts back every cut edge that doesn't close a cycle with the
+// edges that are kept, starting with the most expensive ones, since the
+// heuristics can cut more than they need to. Cuts that are nil or empty stay
+// empty.
+func minimalCuts(edges, cuts []Edge) []Edge {
+	cut := map[[2]string]bool{}
+	for _, e := range cuts {
+		cut[[2]string{e.From, e.To}] = true
+	}
+	adjacent := map[string][]string{}
+	for _, e := range edges {
+		if !cut[[2]string{e.From, e.To}] {
+			adjacent[e.From] = append(adjacent[e.From], e.To)
+		}
+	}
+
+	cuts = append([]Edge{}, cuts...)
+	sort.SliceStable(cuts, func(i, j int) bool {
+		return cuts[i].Weight > cuts[j].Weight
+	})
+
+	needed := []Edge{}
+	for _, e := range cuts {
+		if reaches(adjacent, e.To, e.From) {
+			needed = append(needed, e)
+		} else {
+			adjacent[e.From] = append(adjacent[e.From], e.To)
+		}
+	}
+	return needed
+}
+
+func totalWeight(edges []Edge) int {
+	weight := 0
+	for _, e := range edges {
+		weight += e.Weight
+	}
+	return weight
+}
+
+func containsEdge(edges []Edge, e Edge) bool {
+	for _, other := range edges {
+		if other == e {
+			return true
+		}
+	}
+	return false
+}
+
+// reaches returns whether there's a path from one node to another in an
+// adjacency list.
+func reaches(adjacent map[string][]string, from, to string) bool {
+	visited := map[string]bool{from: true}
+	stack := []string{from}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node == to {
+			return true
+		}
+		for _, next := range adjacent[node] {
+			if !visited[next] {
+				visited[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCycle_Cuts(t *testing.T) {
+	t.Run("cuts the cheapest edge of a simple cycle", func(tt *testing.T) {
+		c := Cycle{
+			Nodes: []string{"a.go", "b.go", "c.go"},
+			Edges: []Edge{
+				{From: "a.go", To: "b.go", Weight: 5},
+				{From: "b.go", To: "c.go", Weight: 1},
+				{From: "c.go", To: "a.go", Weight: 3},
+			},
+		}
+
+		assert.Equal(tt, []Edge{{From: "b.go", To: "c.go", Weight: 1}}, c.Cuts())
+	})
+
+	t.Run("breaks every cycle with the fewest references", func(tt *testing.T) {
+		// There are three overlapping cycles through a.go, and the cheapest
+		// way to break all of them avoids both of the heavy edges.
+		c := Cycle{
+			Nodes: []string{"a.go", "b.go", "c.go"},
+			Edges: []Edge{
+				{From: "a.go", To: "b.go", Weight: 4},
+				{From: "a.go", To: "c.go", Weight: 1},
+				{From: "b.go", To: "a.go", Weight: 2},
+				{From: "b.go", To: "c.go", Weight: 1},
+				{From: "c.go", To: "a.go", Weight: 6},
+			},
+		}
+
+		cuts := c.Cuts()
+		assert.Equal(tt, []Edge{
+			{From: "a.go", To: "c.go", Weight: 1},
+			{From: "b.go", To: "c.go", Weight: 1},
+			{From: "b.go", To: "a.go", Weight: 2},
+		}, cuts)
+
+		kept := []Edge{}
+		for _, e := range c.Edges {
+			if !containsEdge(cuts, e) {
+				kept = append(kept, e)
+			}
+		}
+		assert.Empty(tt, build(nil, kept).Cycles())
+	})
+}
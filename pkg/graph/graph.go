@@ -0,0 +1,307 @@
+package graph
+
+import (
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+)
+
+// Graph is a directed graph built from links. The nodes are either files or,
+// once aggregated, the package directories that contain them.
+type Graph struct {
+	// Nodes is the list of files (or packages), sorted by ID.
+	Nodes []Node
+	// Edges is the sorted list of edges between the nodes.
+	Edges []Edge
+}
+
+type Node struct {
+	// ID is the filename relative from the root, or the directory relative
+	// from the root for packages.
+	ID string
+	// Package is the path of the package that the node is in. This is only
+	// known when the graph is built from an analysis.
+	Package string
+	// Test is whether the node is a _test.go file, or a package that only
+	// contains test files.
+	Test bool
+	// Lines is the number of lines in the file, or in all of the package's
+	// files.
+	Lines int
+	// Types is the number of exported types that are declared in the file, or
+	// in all of the package's files. Like the package, this is only known when
+	// the graph is built from an analysis.
+	Types int
+	// Interfaces is how many of the exported types are interfaces.
+	Interfaces int
+}
+
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Weight is the number of references that this edge represents. Graphs
+	// built from plain links don't know about references, so every link has a
+	// weight of 1. Aggregating to packages adds up the weights of all the
+	// edges between the files in both packages.
+	Weight int `json:"weight"`
+	// Kind is the kind of link. Aggregated edges only keep their kind if all
+	// the edges that were merged into them had the same kind.
+	Kind links.LinkKind `json:"kind"`
+}
+
+// New builds a file graph from a list of links.
+func New(ls []links.Link) *Graph {
+	edges := make([]Edge, 0, len(ls))
+	for _, l := range ls {
+		edges = append(edges, Edge{From: l.From, To: l.To, Weight: 1})
+	}
+	return build(nil, edges)
+}
+
+// FromAnalysis builds a file graph from an analysis. Unlike New, the graph
+// includes files that don't have any links, and the nodes and edges have all
+// their attributes filled in.
+func FromAnalysis(a *links.Analysis) *Graph {
+	nodes := make([]Node, 0, len(a.Files))
+	for _, f := range a.Files {
+		n := Node{
+			ID:      f.Name,
+			Package: f.Package,
+			Test:    f.Test,
+			Lines:   f.Lines,
+		}
+		for _, s := range f.Symbols {
+			if !token.IsExported(s.Name) {
+				continue
+			}
+			switch s.Kind {
+			case links.SymbolKindInterface:
+				n.Types++
+				n.Interfaces++
+			case links.SymbolKindStruct, links.SymbolKindType:
+				n.Types++
+			}
+		}
+		nodes = append(nodes, n)
+	}
+
+	edges := make([]Edge, 0, len(a.Edges))
+	for _, e := range a.Edges {
+		edges = append(edges, Edge{
+			From:   e.From,
+			To:     e.To,
+			Weight: e.Weight(),
+			Kind:   e.Kind,
+		})
+	}
+
+	return build(nodes, edges)
+}
+
+// Packages aggregates the graph so that every node is the directory of the
+// package it's in. Links within the same package are dropped since they would
+// only show up as self-loops.
+func (g *Graph) Packages() *Graph {
+	return g.aggregate(path.Dir)
+}
+
+// Directories aggregates the graph so that every node is a directory that's at
+// most depth levels deep. Files in shallower directories are aggregated to the
+// directory they're in. For example, with a depth of 1, pkg/links/links.go and
+// pkg/parser/parser.go are both aggregated to pkg, and main.go is aggregated to
+// the root directory ".". Links within the same directory are dropped.
+func (g *Graph) Directories(depth int) *Graph {
+	return g.aggregate(func(id string) string {
+		segments := strings.Split(path.Dir(id), "/")
+		if len(segments) > depth {
+			segments = segments[:depth]
+		}
+		if len(segments) == 0 {
+			return "."
+		}
+		return strings.Join(segments, "/")
+	})
+}
+
+// aggregate merges every node into the node that key returns for its ID, and
+// drops the edges that end up as self-loops.
+func (g *Graph) aggregate(key func(id string) string) *Graph {
+	nodes := map[string]*Node{}
+	for _, n := range g.Nodes {
+		id := key(n.ID)
+		agg, ok := nodes[id]
+		if !ok {
+			agg = &Node{ID: id, Package: n.Package, Test: true}
+			nodes[id] = agg
+		}
+		if agg.Package != n.Package {
+			// The package is only kept if every node is in the same one.
+			agg.Package = ""
+		}
+		agg.Test = agg.Test && n.Test
+		agg.Lines += n.Lines
+		agg.Types += n.Types
+		agg.Interfaces += n.Interfaces
+	}
+
+	edges := []Edge{}
+	for _, e := range g.Edges {
+		from := key(e.From)
+		to := key(e.To)
+		if from == to {
+			continue
+		}
+		edges = append(edges, Edge{From: from, To: to, Weight: e.Weight, Kind: e.Kind})
+	}
+
+	return build(nodeList(nodes), edges)
+}
+
+// Focus limits the graph to the nodes within the dir subtree and the edges
+// between them. An empty dir or "." keeps the whole graph.
+func (g *Graph) Focus(dir string) *Graph {
+	dir = path.Clean(dir)
+	if dir == "." {
+		return g
+	}
+
+	nodes := []Node{}
+	for _, n := range g.Nodes {
+		if inDir(n.ID, dir) {
+			nodes = append(nodes, n)
+		}
+	}
+
+	edges := []Edge{}
+	for _, e := range g.Edges {
+		if inDir(e.From, dir) && inDir(e.To, dir) {
+			edges = append(edges, e)
+		}
+	}
+
+	return build(nodes, edges)
+}
+
+// WithoutTests removes the test nodes from the graph along with their edges.
+// This should be done before aggregating to packages, since only packages that
+// contain nothing but tests are test nodes once they're aggregated.
+func (g *Graph) WithoutTests() *Graph {
+	tests := map[string]bool{}
+	nodes := []Node{}
+	for _, n := range g.Nodes {
+		if n.Test {
+			tests[n.ID] = true
+		} else {
+			nodes = append(nodes, n)
+		}
+	}
+
+	edges := []Edge{}
+	for _, e := range g.Edges {
+		if !tests[e.From] && !tests[e.To] {
+			edges = append(edges, e)
+		}
+	}
+
+	return build(nodes, edges)
+}
+
+// Node returns the node with the given ID, or nil if there isn't one.
+func (g *Graph) Node(id string) *Node {
+	i := sort.Search(len(g.Nodes), func(i int) bool {
+		return g.Nodes[i].ID >= id
+	})
+	if i < len(g.Nodes) && g.Nodes[i].ID == id {
+		return &g.Nodes[i]
+	}
+	return nil
+}
+
+// inDir returns whether the node is dir itself or is somewhere under it.
+func inDir(node, dir string) bool {
+	return node == dir || strings.HasPrefix(node, dir+"/")
+}
+
+func nodeList(nodes map[string]*Node) []Node {
+	list := make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		list = append(list, *n)
+	}
+	return list
+}
+
+// build merges duplicate edges, summing their weights, and then generates the
+// sorted list of nodes and edges. Any node that's at either end of an edge but
+// isn't in nodes is added with only its ID.
+func build(nodes []Node, edges []Edge) *Graph {
+	type key struct{ from, to string }
+
+	merged := map[key]*Edge{}
+	nodeSet := map[string]*Node{}
+	for i := range nodes {
+		nodeSet[nodes[i].ID] = &nodes[i]
+	}
+	for _, e := range edges {
+		k := key{e.From, e.To}
+		if m, ok := merged[k]; ok {
+			m.Weight += e.Weight
+			if m.Kind != e.Kind {
+				m.Kind = ""
+			}
+		} else {
+			e := e
+			merged[k] = &e
+		}
+
+		for _, id := range []string{e.From, e.To} {
+			if _, ok := nodeSet[id]; !ok {
+				nodeSet[id] = &Node{ID: id}
+			}
+		}
+	}
+
+	g := &Graph{
+		Nodes: nodeList(nodeSet),
+		Edges: make([]Edge, 0, len(merged)),
+	}
+	for _, e := range merged {
+		g.Edges = append(g.Edges, *e)
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From == g.Edges[j].From {
+			return g.Edges[i].To < g.Edges[j].To
+		}
+		return g.Edges[i].From < g.Edges[j].From
+	})
+
+	return g
+}
+
+// ExternalEdges returns an edge from every package directory to every external
+// module that any of its files import. The weight is the number of files in the
+// package that import something from that module.
+func ExternalEdges(a *links.Analysis) []Edge {
+	edges := []Edge{}
+	for _, f := range a.Files {
+		modules := map[string]struct{}{}
+		for _, importPath := range f.Imports {
+			if module, ok := a.ExternalModule(f, importPath); ok {
+				modules[module] = struct{}{}
+			}
+		}
+		for module := range modules {
+			edges = append(edges, Edge{From: path.Dir(f.Name), To: module, Weight: 1})
+		}
+	}
+
+	// build merges the edges for files in the same package, but the nodes it
+	// adds for the modules aren't needed.
+	return build(nil, edges).Edges
+}
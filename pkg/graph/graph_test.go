@@ -0,0 +1,176 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+)
+
+var testLinks = []links.Link{
+	{From: "cmd/api/main.go", To: "pkg/server/server.go"},
+	{From: "cmd/api/main.go", To: "pkg/signals/signals.go"},
+	{From: "pkg/server/routes.go", To: "pkg/server/server.go"},
+	{From: "pkg/server/server.go", To: "pkg/handlers/handlers.go"},
+	{From: "pkg/server/routes.go", To: "pkg/handlers/handlers.go"},
+	{From: "pkg/signals/signals_test.go", To: "pkg/signals/signals.go"},
+}
+
+func TestNew(t *testing.T) {
+	t.Run("collects every node from the links", func(tt *testing.T) {
+		g := New(testLinks)
+
+		assert.Equal(tt, []string{
+			"cmd/api/main.go",
+			"pkg/handlers/handlers.go",
+			"pkg/server/routes.go",
+			"pkg/server/server.go",
+			"pkg/signals/signals.go",
+			"pkg/signals/signals_test.go",
+		}, nodeIDs(g))
+		assert.Len(tt, g.Edges, len(testLinks))
+	})
+}
+
+func TestGraph_Packages(t *testing.T) {
+	t.Run("aggregates files to their package directories", func(tt *testing.T) {
+		g := New(testLinks).Packages()
+
+		assert.Equal(tt, []string{"cmd/api", "pkg/handlers", "pkg/server", "pkg/signals"}, nodeIDs(g))
+		assert.Equal(tt, []Edge{
+			{From: "cmd/api", To: "pkg/server", Weight: 1},
+			{From: "cmd/api", To: "pkg/signals", Weight: 1},
+			{From: "pkg/server", To: "pkg/handlers", Weight: 2},
+		}, g.Edges)
+	})
+}
+
+func TestGraph_Focus(t *testing.T) {
+	t.Run("keeps only the edges within the subtree", func(tt *testing.T) {
+		g := New(testLinks).Focus("pkg/server/")
+
+		assert.Equal(tt, []string{"pkg/server/routes.go", "pkg/server/server.go"}, nodeIDs(g))
+		assert.Equal(tt, []Edge{
+			{From: "pkg/server/routes.go", To: "pkg/server/server.go", Weight: 1},
+		}, g.Edges)
+	})
+
+	t.Run("keeps the whole graph for the root", func(tt *testing.T) {
+		g := New(testLinks)
+
+		assert.Equal(tt, g, g.Focus(""))
+	})
+}
+
+func TestGraph_WithoutTests(t *testing.T) {
+	t.Run("removes the test files and their edges", func(tt *testing.T) {
+		g := FromAnalysis(&links.Analysis{
+			Files: []links.File{
+				{Name: "a.go"},
+				{Name: "a_test.go", Test: true},
+				{Name: "b.go"},
+			},
+			Edges: []links.Edge{
+				{From: "a.go", To: "b.go"},
+				{From: "a_test.go", To: "a.go"},
+			},
+		}).WithoutTests()
+
+		assert.Equal(tt, []string{"a.go", "b.go"}, nodeIDs(g))
+		assert.Equal(tt, []Edge{{From: "a.go", To: "b.go"}}, g.Edges)
+	})
+}
+
+func TestFromAnalysis(t *testing.T) {
+	t.Run("fills in the node and edge attributes", func(tt *testing.T) {
+		a := &links.Analysis{
+			Files: []links.File{
+				{Name: "pkg/a/a.go", Package: "example.com/pkg/a", Lines: 10},
+				{Name: "pkg/a/a_test.go", Package: "example.com/pkg/a", Test: true, Lines: 5},
+				{Name: "pkg/b/b.go", Package: "example.com/pkg/b", Lines: 20, Symbols: []links.Symbol{
+					{Name: "B", Kind: links.SymbolKindStruct},
+					{Name: "Store", Kind: links.SymbolKindInterface},
+					{Name: "store", Kind: links.SymbolKindInterface},
+					{Name: "Names", Kind: links.SymbolKindType},
+					{Name: "New", Kind: links.SymbolKindFunc},
+				}},
+				{Name: "pkg/c/c.go", Package: "example.com/pkg/c", Lines: 1},
+			},
+			Edges: []links.Edge{
+				{
+					From: "pkg/a/a.go",
+					To:   "pkg/b/b.go",
+					Kind: links.LinkKindImport,
+					References: []links.Reference{
+						{Identifier: "B", Line: 3, Column: 4},
+						{Identifier: "B", Line: 5, Column: 4},
+					},
+				},
+				{
+					From:       "pkg/a/a_test.go",
+					To:         "pkg/b/b.go",
+					Kind:       links.LinkKindDotImport,
+					References: []links.Reference{{Identifier: "B", Line: 3, Column: 4}},
+				},
+			},
+		}
+
+		g := FromAnalysis(a)
+		assert.Len(tt, g.Nodes, 4)
+		assert.Equal(tt, &Node{ID: "pkg/a/a_test.go", Package: "example.com/pkg/a", Test: true, Lines: 5}, g.Node("pkg/a/a_test.go"))
+		assert.Equal(tt, &Node{ID: "pkg/b/b.go", Package: "example.com/pkg/b", Lines: 20, Types: 3, Interfaces: 1}, g.Node("pkg/b/b.go"))
+		assert.Equal(tt, Edge{From: "pkg/a/a.go", To: "pkg/b/b.go", Weight: 2, Kind: links.LinkKindImport}, g.Edges[0])
+
+		packages := g.Packages()
+		assert.Equal(tt, []Node{
+			{ID: "pkg/a", Package: "example.com/pkg/a", Lines: 15},
+			{ID: "pkg/b", Package: "example.com/pkg/b", Lines: 20, Types: 3, Interfaces: 1},
+			{ID: "pkg/c", Package: "example.com/pkg/c", Lines: 1},
+		}, packages.Nodes)
+		assert.Equal(tt, []Edge{
+			{From: "pkg/a", To: "pkg/b", Weight: 3},
+		}, packages.Edges)
+	})
+}
+
+func nodeIDs(g *Graph) []string {
+	ids := make([]string, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
+
+func TestExternalEdges(t *testing.T) {
+	t.Run("counts the files in each package that import a module", func(tt *testing.T) {
+		a := &links.Analysis{
+			Files: []links.File{
+				{Name: "pkg/a/a.go", Module: "example.com/app", Imports: []string{"fmt", "github.com/pkg/errors"}},
+				{Name: "pkg/a/b.go", Module: "example.com/app", Imports: []string{"github.com/pkg/errors/sub", "example.com/app/pkg/b"}},
+				{Name: "pkg/b/b.go", Module: "example.com/app", Imports: []string{"golang.org/x/mod/modfile"}},
+			},
+			Modules: []links.Module{
+				{Path: "example.com/app", Requires: []string{"github.com/pkg/errors", "golang.org/x/mod"}},
+			},
+		}
+
+		assert.Equal(tt, []Edge{
+			{From: "pkg/a", To: "github.com/pkg/errors", Weight: 2},
+			{From: "pkg/b", To: "golang.org/x/mod", Weight: 1},
+		}, ExternalEdges(a))
+	})
+}
+
+func TestGraph_Directories(t *testing.T) {
+	t.Run("aggregates files to directories up to a depth", func(tt *testing.T) {
+		g := New(append([]links.Link{
+			{From: "main.go", To: "pkg/server/server.go"},
+		}, testLinks...)).Directories(1)
+
+		assert.Equal(tt, []string{".", "cmd", "pkg"}, nodeIDs(g))
+		assert.Equal(tt, []Edge{
+			{From: ".", To: "pkg", Weight: 1},
+			{From: "cmd", To: "pkg", Weight: 2},
+		}, g.Edges)
+	})
+}
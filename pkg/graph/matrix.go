@@ -0,0 +1,51 @@
+package graph
+
+// Matrix is a dependency structure matrix (DSM) of a graph. Both the rows and
+// the columns are the nodes, in the same order, and every cell is the weight
+// of the edge from the node of its row to the node of its column.
+type Matrix struct {
+	// Nodes is the IDs of the nodes in the order of the rows and columns.
+	Nodes []string
+	// Components is the index of the strongly connected component that every
+	// node is in. Nodes in the same component are next to each other.
+	Components []int
+	// Cells has a row for every node with a column for every node.
+	Cells [][]int
+}
+
+// Matrix returns the dependency structure matrix of the graph. The nodes are
+// ordered so that a node comes after everything it depends on, which means
+// that all the dependencies are below the diagonal unless they're part of a
+// cycle. The nodes in a cycle are kept together, so the dependencies above the
+// diagonal are all in the blocks of the components on the diagonal.
+func (g *Graph) Matrix() *Matrix {
+	m := &Matrix{
+		Nodes:      []string{},
+		Components: []int{},
+		Cells:      [][]int{},
+	}
+
+	position := map[string]int{}
+	for i, component := range g.StronglyConnectedComponents() {
+		for _, id := range component {
+			position[id] = len(m.Nodes)
+			m.Nodes = append(m.Nodes, id)
+			m.Components = append(m.Components, i)
+		}
+	}
+
+	for range m.Nodes {
+		m.Cells = append(m.Cells, make([]int, len(m.Nodes)))
+	}
+	for _, e := range g.Edges {
+		m.Cells[position[e.From]][position[e.To]] += e.Weight
+	}
+
+	return m
+}
+
+// Cyclic is whether the node at index i is part of a cycle with other nodes.
+func (m *Matrix) Cyclic(i int) bool {
+	return (i > 0 && m.Components[i-1] == m.Components[i]) ||
+		(i < len(m.Components)-1 && m.Components[i+1] == m.Components[i])
+}
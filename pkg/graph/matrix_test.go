@@ -0,0 +1,34 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_Matrix(t *testing.T) {
+	t.Run("orders the nodes after their dependencies", func(tt *testing.T) {
+		g := New([]links.Link{
+			{From: "a.go", To: "b.go"},
+			{From: "b.go", To: "a.go"},
+			{From: "b.go", To: "c.go"},
+			{From: "d.go", To: "a.go"},
+		})
+
+		m := g.Matrix()
+
+		assert.Equal(tt, []string{"c.go", "a.go", "b.go", "d.go"}, m.Nodes)
+		assert.Equal(tt, []int{0, 1, 1, 2}, m.Components)
+		assert.Equal(tt, [][]int{
+			{0, 0, 0, 0},
+			{0, 0, 1, 0},
+			{1, 1, 0, 0},
+			{0, 1, 0, 0},
+		}, m.Cells)
+		assert.False(tt, m.Cyclic(0))
+		assert.True(tt, m.Cyclic(1))
+		assert.True(tt, m.Cyclic(2))
+		assert.False(tt, m.Cyclic(3))
+	})
+}
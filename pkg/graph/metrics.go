@@ -0,0 +1,62 @@
+package graph
+
+import "math"
+
+// Metrics are the coupling and stability metrics of a node. They're usually
+// computed for packages, where they're Robert C. Martin's package metrics, but
+// the fan-in and fan-out work for files too.
+type Metrics struct {
+	ID string `json:"id"`
+	// FanIn is the number of other nodes that depend on this one. For
+	// packages, this is the afferent coupling (Ca).
+	FanIn int `json:"fanIn"`
+	// FanOut is the number of other nodes that this one depends on. For
+	// packages, this is the efferent coupling (Ce).
+	FanOut int `json:"fanOut"`
+	// Instability is FanOut / (FanIn + FanOut), from 0 for a node that only
+	// has dependents to 1 for a node that only has dependencies. It's 0 for a
+	// node without either.
+	Instability float64 `json:"instability"`
+	// Abstractness is the share of interfaces among the exported types, from
+	// 0 for only concrete types to 1 for only interfaces. It's 0 for a node
+	// without any exported types.
+	Abstractness float64 `json:"abstractness"`
+	// Distance is the distance from the main sequence, |A + I - 1|. Nodes close
+	// to 0 balance how abstract they are with how many nodes depend on them,
+	// while nodes close to 1 are either concrete and depended on by a lot (the
+	// zone of pain) or abstract with nothing depending on them (the zone of
+	// uselessness).
+	Distance float64 `json:"distance"`
+}
+
+// Metrics returns the metrics of every node, in the same order as the nodes.
+// The fan-in and fan-out count nodes rather than edges or references, so
+// aggregate the graph to packages first for the package metrics.
+func (g *Graph) Metrics() []Metrics {
+	fanIn := map[string]int{}
+	fanOut := map[string]int{}
+	for _, e := range g.Edges {
+		// Edges are merged when the graph is built, so every edge is between
+		// a different pair of nodes.
+		fanIn[e.To]++
+		fanOut[e.From]++
+	}
+
+	metrics := make([]Metrics, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		m := Metrics{
+			ID:     n.ID,
+			FanIn:  fanIn[n.ID],
+			FanOut: fanOut[n.ID],
+		}
+		if m.FanIn+m.FanOut > 0 {
+			m.Instability = float64(m.FanOut) / float64(m.FanIn+m.FanOut)
+		}
+		if n.Types > 0 {
+			m.Abstractness = float64(n.Interfaces) / float64(n.Types)
+		}
+		m.Distance = math.Abs(m.Abstractness + m.Instability - 1)
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph_Metrics(t *testing.T) {
+	t.Run("computes the package metrics", func(tt *testing.T) {
+		g := build([]Node{
+			{ID: "cmd/api"},
+			{ID: "pkg/domain", Types: 4, Interfaces: 3},
+			{ID: "pkg/db", Types: 2},
+			{ID: "pkg/unused", Types: 1, Interfaces: 1},
+		}, []Edge{
+			{From: "cmd/api", To: "pkg/domain", Weight: 3},
+			{From: "cmd/api", To: "pkg/db", Weight: 1},
+			{From: "pkg/db", To: "pkg/domain", Weight: 2},
+		})
+
+		metrics := g.Metrics()
+		require.Len(tt, metrics, 4)
+
+		assert.Equal(tt, Metrics{ID: "cmd/api", FanIn: 0, FanOut: 2, Instability: 1, Abstractness: 0, Distance: 0}, metrics[0])
+		assert.Equal(tt, "pkg/db", metrics[1].ID)
+		assert.Equal(tt, 1, metrics[1].FanIn)
+		assert.Equal(tt, 1, metrics[1].FanOut)
+		assert.InDelta(tt, 0.5, metrics[1].Instability, 1e-9)
+		assert.InDelta(tt, 0.5, metrics[1].Distance, 1e-9)
+		assert.Equal(tt, "pkg/domain", metrics[2].ID)
+		assert.Equal(tt, 2, metrics[2].FanIn)
+		assert.InDelta(tt, 0, metrics[2].Instability, 1e-9)
+		assert.InDelta(tt, 0.75, metrics[2].Abstractness, 1e-9)
+		assert.InDelta(tt, 0.25, metrics[2].Distance, 1e-9)
+		// Without any links, the instability is 0 rather than undefined.
+		assert.Equal(tt, Metrics{ID: "pkg/unused", Abstractness: 1, Distance: 0}, metrics[3])
+	})
+
+	t.Run("counts the nodes rather than the references", func(tt *testing.T) {
+		metrics := New(testLinks).Packages().Metrics()
+
+		require.Equal(tt, "pkg/server", metrics[2].ID)
+		assert.Equal(tt, 1, metrics[2].FanIn)
+		// The edge to pkg/handlers has a weight of 2, but it's a single
+		// package.
+		assert.Equal(tt, 1, metrics[2].FanOut)
+		assert.InDelta(tt, 0.5, metrics[2].Instability, 1e-9)
+	})
+}
@@ -0,0 +1,322 @@
+package graph
+
+import (
+	"path"
+	"sort"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+)
+
+// ShortestPath returns the shortest chain of nodes that leads from one node to
+// another, including both ends. Either end can also be a directory, in which
+// case the path starts or ends at whichever node in it is the closest. When
+// several paths are equally short, the one that comes first in the order of
+// the edges is returned. It returns nil if there's no path.
+func (g *Graph) ShortestPath(from, to string) []string {
+	// This is a breadth-first search from all the nodes in from at once, which
+	// means the first node in to that's reached is the closest one.
+	adjacent := g.adjacency()
+	previous := map[string]string{}
+	visited := map[string]bool{}
+	queue := []string{}
+	for _, n := range g.Nodes {
+		if inDir(n.ID, from) {
+			visited[n.ID] = true
+			queue = append(queue, n.ID)
+		}
+	}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		if inDir(node, to) {
+			path := []string{node}
+			for !inDir(node, from) {
+				node = previous[node]
+				path = append([]string{node}, path...)
+			}
+			return path
+		}
+
+		for _, next := range adjacent[node] {
+			if !visited[next] {
+				visited[next] = true
+				previous[next] = node
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Dependent is a node that depends on another one, either directly or through
+// other nodes.
+type Dependent struct {
+	ID string `json:"id"`
+	// Depth is the number of links between the dependent and the closest of
+	// the nodes it depends on, so direct dependents have a depth of 1.
+	Depth int `json:"depth"`
+}
+
+// Dependents returns every node that transitively depends on any of the given
+// nodes, which is everything that could be affected by changing them. The
+// nodes can also be directories to include every node in them. The given nodes
+// themselves aren't included, and the dependents are sorted by depth and ID.
+func (g *Graph) Dependents(ids []string) []Dependent {
+	// This is a breadth-first search over the reversed edges, so every node is
+	// first reached through its shortest path.
+	reverse := map[string][]string{}
+	for _, e := range g.Edges {
+		reverse[e.To] = append(reverse[e.To], e.From)
+	}
+
+	depths := map[string]int{}
+	queue := []string{}
+	for _, n := range g.Nodes {
+		for _, id := range ids {
+			if inDir(n.ID, id) {
+				depths[n.ID] = 0
+				queue = append(queue, n.ID)
+				break
+			}
+		}
+	}
+
+	dependents := []Dependent{}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, next := range reverse[node] {
+			if _, ok := depths[next]; !ok {
+				depths[next] = depths[node] + 1
+				dependents = append(dependents, Dependent{ID: next, Depth: depths[next]})
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	sortDependents(dependents)
+	return dependents
+}
+
+// PackageDependents aggregates dependents to the directories of their packages.
+// The depth of a package is the depth of its closest file.
+func PackageDependents(dependents []Dependent) []Dependent {
+	depths := map[string]int{}
+	for _, d := range dependents {
+		dir := path.Dir(d.ID)
+		if depth, ok := depths[dir]; !ok || d.Depth < depth {
+			depths[dir] = d.Depth
+		}
+	}
+
+	packages := make([]Dependent, 0, len(depths))
+	for dir, depth := range depths {
+		packages = append(packages, Dependent{ID: dir, Depth: depth})
+	}
+	sortDependents(packages)
+	return packages
+}
+
+func sortDependents(dependents []Dependent) {
+	sort.Slice(dependents, func(i, j int) bool {
+		if dependents[i].Depth == dependents[j].Depth {
+			return dependents[i].ID < dependents[j].ID
+		}
+		return dependents[i].Depth < dependents[j].Depth
+	})
+}
+
+// AffectedTests are the tests that need to run after some files changed.
+type AffectedTests struct {
+	// Packages is the directory of every package with tests that contains a
+	// changed file or that imports one, directly or not. All of its tests are
+	// affected since they're compiled together with the whole package.
+	Packages []string `json:"packages"`
+	// Files is every test file in those packages that was changed or depends
+	// on a changed file through its links. Since methods don't have links of
+	// their own, every test file of a package is included when none of them
+	// are linked to the changes.
+	Files []string `json:"files"`
+}
+
+// FindAffectedTests returns the tests that are affected by changing the given
+// files, which are relative from the root of the analysis. The packages are
+// selected through their imports rather than the links between files, so that
+// a changed file affects every package that imports its package, even if it
+// only declares methods.
+//
+// The changed files can also be directories. A changed file that isn't a Go
+// file, like an embedded asset or a file under testdata, affects the package
+// it's in, and a Go file that isn't in the analysis, e.g. because it was
+// deleted, affects the package of its directory. A changed go.mod or go.sum
+// affects every package in its module.
+func FindAffectedTests(a *links.Analysis, changed []string) AffectedTests {
+	// Packages are identified by their directory, which includes their
+	// external _test package.
+	dirs := map[string]string{}
+	importedBy := map[string]map[string]bool{}
+	for _, f := range a.Files {
+		dirs[f.Package] = path.Dir(f.Name)
+	}
+	for _, f := range a.Files {
+		for _, importPath := range f.Imports {
+			dir, ok := dirs[importPath]
+			if !ok {
+				continue
+			}
+			if importedBy[dir] == nil {
+				importedBy[dir] = map[string]bool{}
+			}
+			importedBy[dir][path.Dir(f.Name)] = true
+		}
+	}
+	isPackage := map[string]bool{}
+	for _, dir := range dirs {
+		isPackage[dir] = true
+	}
+
+	affected := map[string]bool{}
+	for _, id := range changed {
+		base := path.Base(id)
+		switch {
+		case base == "go.mod" || base == "go.sum":
+			for dir := range isPackage {
+				if inDir(dir, path.Dir(id)) || path.Dir(id) == "." {
+					affected[dir] = true
+				}
+			}
+		case path.Ext(id) == ".go":
+			affected[path.Dir(id)] = true
+		default:
+			found := false
+			for dir := range isPackage {
+				if inDir(dir, id) {
+					affected[dir] = true
+					found = true
+				}
+			}
+			if !found {
+				// This is a file, or a directory without any Go files, so the
+				// closest package that it's in is affected.
+				for dir := path.Dir(id); ; dir = path.Dir(dir) {
+					if isPackage[dir] {
+						affected[dir] = true
+						break
+					}
+					if dir == "." {
+						break
+					}
+				}
+			}
+		}
+	}
+
+	queue := make([]string, 0, len(affected))
+	for dir := range affected {
+		queue = append(queue, dir)
+	}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		for importer := range importedBy[dir] {
+			if !affected[importer] {
+				affected[importer] = true
+				queue = append(queue, importer)
+			}
+		}
+	}
+
+	g := FromAnalysis(a)
+	linked := map[string]bool{}
+	for _, n := range g.Nodes {
+		for _, id := range changed {
+			if inDir(n.ID, id) {
+				linked[n.ID] = true
+			}
+		}
+	}
+	for _, d := range g.Dependents(changed) {
+		linked[d.ID] = true
+	}
+
+	testFiles := map[string][]string{}
+	linkedTestFiles := map[string][]string{}
+	for _, n := range g.Nodes {
+		dir := path.Dir(n.ID)
+		if !n.Test || !affected[dir] {
+			continue
+		}
+		testFiles[dir] = append(testFiles[dir], n.ID)
+		if linked[n.ID] {
+			linkedTestFiles[dir] = append(linkedTestFiles[dir], n.ID)
+		}
+	}
+
+	tests := AffectedTests{Packages: []string{}, Files: []string{}}
+	for dir, files := range testFiles {
+		tests.Packages = append(tests.Packages, dir)
+		// When none of the package's test files are linked to the changes,
+		// e.g. because the package only uses a changed method, any of them
+		// could be affected.
+		if linked := linkedTestFiles[dir]; len(linked) > 0 {
+			files = linked
+		}
+		tests.Files = append(tests.Files, files...)
+	}
+	sort.Strings(tests.Packages)
+	sort.Strings(tests.Files)
+
+	return tests
+}
+
+// Orphans returns the IDs of the nodes that nothing depends on.
+func (g *Graph) Orphans() []string {
+	used := map[string]bool{}
+	for _, e := range g.Edges {
+		used[e.To] = true
+	}
+
+	orphans := []string{}
+	for _, n := range g.Nodes {
+		if !used[n.ID] {
+			orphans = append(orphans, n.ID)
+		}
+	}
+	return orphans
+}
+
+// Unreachable returns the IDs of the nodes that can't be reached by following
+// the edges from any of the roots.
+func (g *Graph) Unreachable(roots []string) []string {
+	adjacent := g.adjacency()
+	reached := map[string]bool{}
+	stack := []string{}
+	for _, id := range roots {
+		if !reached[id] {
+			reached[id] = true
+			stack = append(stack, id)
+		}
+	}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, next := range adjacent[node] {
+			if !reached[next] {
+				reached[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+
+	unreachable := []string{}
+	for _, n := range g.Nodes {
+		if !reached[n.ID] {
+			unreachable = append(unreachable, n.ID)
+		}
+	}
+	return unreachable
+}
@@ -0,0 +1,191 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_ShortestPath(t *testing.T) {
+	g := New([]links.Link{
+		{From: "cmd/api/main.go", To: "pkg/server/server.go"},
+		{From: "cmd/api/main.go", To: "pkg/config/config.go"},
+		{From: "pkg/config/config.go", To: "pkg/db/db.go"},
+		{From: "pkg/server/server.go", To: "pkg/handlers/handlers.go"},
+		{From: "pkg/handlers/handlers.go", To: "pkg/db/db.go"},
+	})
+
+	t.Run("returns the shortest path between two files", func(tt *testing.T) {
+		path := g.ShortestPath("cmd/api/main.go", "pkg/db/db.go")
+		assert.Equal(tt, []string{"cmd/api/main.go", "pkg/config/config.go", "pkg/db/db.go"}, path)
+	})
+
+	t.Run("ends at the closest file in a directory", func(tt *testing.T) {
+		path := g.ShortestPath("cmd/api/main.go", "pkg/handlers")
+		assert.Equal(tt, []string{"cmd/api/main.go", "pkg/server/server.go", "pkg/handlers/handlers.go"}, path)
+	})
+
+	t.Run("returns nil without a path", func(tt *testing.T) {
+		path := g.ShortestPath("pkg/db/db.go", "cmd/api/main.go")
+		assert.Nil(tt, path)
+	})
+}
+
+func TestGraph_Dependents(t *testing.T) {
+	g := New([]links.Link{
+		{From: "cmd/api/main.go", To: "pkg/server/server.go"},
+		{From: "cmd/api/main.go", To: "pkg/config/config.go"},
+		{From: "pkg/config/config.go", To: "pkg/db/db.go"},
+		{From: "pkg/server/server.go", To: "pkg/handlers/handlers.go"},
+		{From: "pkg/handlers/handlers.go", To: "pkg/db/db.go"},
+		{From: "pkg/handlers/handlers_test.go", To: "pkg/handlers/handlers.go"},
+	})
+
+	t.Run("returns the transitive dependents with their depth", func(tt *testing.T) {
+		dependents := g.Dependents([]string{"pkg/db/db.go"})
+
+		assert.Equal(tt, []Dependent{
+			{ID: "pkg/config/config.go", Depth: 1},
+			{ID: "pkg/handlers/handlers.go", Depth: 1},
+			{ID: "cmd/api/main.go", Depth: 2},
+			{ID: "pkg/handlers/handlers_test.go", Depth: 2},
+			{ID: "pkg/server/server.go", Depth: 2},
+		}, dependents)
+	})
+
+	t.Run("starts from every file in a directory", func(tt *testing.T) {
+		dependents := g.Dependents([]string{"pkg/server", "pkg/config"})
+
+		assert.Equal(tt, []Dependent{
+			{ID: "cmd/api/main.go", Depth: 1},
+		}, dependents)
+	})
+
+	t.Run("aggregates the dependents to packages", func(tt *testing.T) {
+		dependents := PackageDependents(g.Dependents([]string{"pkg/db/db.go"}))
+
+		assert.Equal(tt, []Dependent{
+			{ID: "pkg/config", Depth: 1},
+			{ID: "pkg/handlers", Depth: 1},
+			{ID: "cmd/api", Depth: 2},
+			{ID: "pkg/server", Depth: 2},
+		}, dependents)
+	})
+}
+
+func TestFindAffectedTests(t *testing.T) {
+	a := &links.Analysis{
+		Files: []links.File{
+			{Name: "pkg/db/db.go", Package: "example.com/pkg/db"},
+			{Name: "pkg/db/db_test.go", Package: "example.com/pkg/db", Test: true},
+			{Name: "pkg/handlers/handlers.go", Package: "example.com/pkg/handlers", Imports: []string{"example.com/pkg/db"}},
+			{Name: "pkg/handlers/handlers_test.go", Package: "example.com/pkg/handlers", Test: true},
+			{Name: "pkg/handlers/routes_test.go", Package: "example.com/pkg/handlers", Test: true},
+			{Name: "pkg/server/server.go", Package: "example.com/pkg/server", Imports: []string{"example.com/pkg/handlers", "net/http"}},
+			{Name: "pkg/server/server_test.go", Package: "example.com/pkg/server", Test: true},
+			{Name: "pkg/version/version.go", Package: "example.com/pkg/version"},
+			{Name: "pkg/version/version_test.go", Package: "example.com/pkg/version", Test: true},
+		},
+		Edges: []links.Edge{
+			{From: "pkg/db/db_test.go", To: "pkg/db/db.go"},
+			{From: "pkg/handlers/handlers.go", To: "pkg/db/db.go"},
+			{From: "pkg/handlers/handlers_test.go", To: "pkg/handlers/handlers.go"},
+			{From: "pkg/server/server.go", To: "pkg/handlers/handlers.go"},
+			{From: "pkg/server/server_test.go", To: "pkg/server/server.go"},
+		},
+	}
+
+	t.Run("returns the packages that import the changed package", func(tt *testing.T) {
+		tests := FindAffectedTests(a, []string{"pkg/db/db.go"})
+
+		assert.Equal(tt, AffectedTests{
+			Packages: []string{"pkg/db", "pkg/handlers", "pkg/server"},
+			Files:    []string{"pkg/db/db_test.go", "pkg/handlers/handlers_test.go", "pkg/server/server_test.go"},
+		}, tests)
+	})
+
+	t.Run("includes the importers of a file that only declares methods", func(tt *testing.T) {
+		a := &links.Analysis{
+			Files: []links.File{
+				{Name: "p/a.go", Package: "example.com/p", Symbols: []links.Symbol{
+					{Name: "T", Kind: links.SymbolKindStruct},
+					{Name: "New", Kind: links.SymbolKindFunc},
+				}},
+				{Name: "p/b.go", Package: "example.com/p", Symbols: []links.Symbol{
+					{Name: "M", Kind: links.SymbolKindMethod, Receiver: "T"},
+				}},
+				{Name: "q/q.go", Package: "example.com/q", Imports: []string{"example.com/p"}},
+				{Name: "q/q_test.go", Package: "example.com/q", Test: true},
+			},
+			// q.go calls p.New().M(), but only New has a link.
+			Edges: []links.Edge{
+				{From: "q/q.go", To: "p/a.go"},
+				{From: "q/q_test.go", To: "q/q.go"},
+			},
+		}
+
+		tests := FindAffectedTests(a, []string{"p/b.go"})
+
+		assert.Equal(tt, AffectedTests{
+			Packages: []string{"q"},
+			Files:    []string{"q/q_test.go"},
+		}, tests)
+	})
+
+	t.Run("includes the package of a deleted file", func(tt *testing.T) {
+		tests := FindAffectedTests(a, []string{"pkg/server/deleted.go"})
+
+		assert.Equal(tt, AffectedTests{
+			Packages: []string{"pkg/server"},
+			Files:    []string{"pkg/server/server_test.go"},
+		}, tests)
+	})
+
+	t.Run("includes the package of a file that isn't Go", func(tt *testing.T) {
+		tests := FindAffectedTests(a, []string{"pkg/handlers/testdata/request.json"})
+
+		assert.Equal(tt, []string{"pkg/handlers", "pkg/server"}, tests.Packages)
+	})
+
+	t.Run("includes every package for a changed go.mod", func(tt *testing.T) {
+		for _, filename := range []string{"go.mod", "go.sum"} {
+			tests := FindAffectedTests(a, []string{filename})
+
+			assert.Equal(tt, []string{"pkg/db", "pkg/handlers", "pkg/server", "pkg/version"}, tests.Packages)
+		}
+	})
+
+	t.Run("includes the packages in a changed directory", func(tt *testing.T) {
+		tests := FindAffectedTests(a, []string{"pkg/version"})
+
+		assert.Equal(tt, AffectedTests{
+			Packages: []string{"pkg/version"},
+			Files:    []string{"pkg/version/version_test.go"},
+		}, tests)
+	})
+}
+
+func TestGraph_Orphans(t *testing.T) {
+	t.Run("returns the nodes without incoming edges", func(tt *testing.T) {
+		g := New([]links.Link{
+			{From: "cmd/api/main.go", To: "pkg/server/server.go"},
+			{From: "pkg/old/old.go", To: "pkg/server/server.go"},
+		})
+
+		assert.Equal(tt, []string{"cmd/api/main.go", "pkg/old/old.go"}, g.Orphans())
+	})
+}
+
+func TestGraph_Unreachable(t *testing.T) {
+	t.Run("returns the nodes that the roots don't lead to", func(tt *testing.T) {
+		g := New([]links.Link{
+			{From: "cmd/api/main.go", To: "pkg/server/server.go"},
+			{From: "pkg/server/server.go", To: "pkg/handlers/handlers.go"},
+			{From: "pkg/old/old.go", To: "pkg/older/older.go"},
+			{From: "pkg/older/older.go", To: "pkg/old/old.go"},
+		})
+
+		assert.Equal(tt, []string{"pkg/old/old.go", "pkg/older/older.go"}, g.Unreachable([]string{"cmd/api/main.go"}))
+	})
+}
@@ -0,0 +1,358 @@
+package links
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/parser"
+)
+
+// LinkKind describes how a file ends up using an identifier from another file.
+type LinkKind string
+
+const (
+	// LinkKindPackage is a link between two files in the same package, where
+	// the identifier is used without any qualifier.
+	LinkKindPackage LinkKind = "package"
+	// LinkKindImport is a link to a file in an imported package, where the
+	// identifier is qualified with the package name (e.g. parser.New).
+	LinkKindImport LinkKind = "import"
+	// LinkKindDotImport is a link to a file in a package that was imported
+	// with ".", so its identifiers are used without any qualifier.
+	LinkKindDotImport LinkKind = "dot-import"
+)
+
+// Analysis is everything that was learned about a directory while determining
+// its links. All the filenames in it are relative from the root directory.
+type Analysis struct {
+	// Files is every Go file that was parsed successfully, sorted by name.
+	Files []File `json:"files"`
+	// Edges is every link along with the references that caused it, sorted by
+	// from and to filenames.
+	Edges []Edge `json:"edges"`
+	// Modules is every module that the files are in, sorted by path.
+	Modules []Module `json:"modules"`
+	// Diagnostics is every problem that was found while analyzing the files,
+	// sorted by position. The files in a directory with problems are left out
+	// of the rest of the analysis.
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Diagnostic is a problem in a file that kept it from being analyzed, e.g. a
+// syntax error.
+type Diagnostic struct {
+	// File is the filename relative from the root.
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+type Module struct {
+	// Path is the module path from the go.mod file. It's empty for files that
+	// aren't in a module.
+	Path string `json:"path"`
+	// Requires is the list of module paths that are required in the go.mod
+	// file.
+	Requires []string `json:"requires"`
+}
+
+type File struct {
+	// Name is the filename relative from the root, e.g. pkg/parser/parser.go.
+	Name string `json:"name"`
+	// Package is the path of the package that the file is in, e.g.
+	// github.com/Codesee-io/codesee-deps-go/pkg/parser.
+	Package string `json:"package"`
+	// PackageName is the name in the package clause of the file, e.g. parser,
+	// or main for commands.
+	PackageName string `json:"packageName"`
+	// Module is the path of the module that the file is in, as defined in the
+	// closest go.mod file. It's empty if there isn't a go.mod file.
+	Module string `json:"module"`
+	// Test is whether this is a _test.go file.
+	Test bool `json:"test"`
+	// Lines is the number of lines in the file.
+	Lines int `json:"lines"`
+	// Imports is the path of every package that the file imports, in the
+	// order they're imported.
+	Imports []string `json:"imports"`
+	// Symbols is every identifier that's declared at the top level of the
+	// file, in the order they're declared.
+	Symbols []Symbol `json:"symbols"`
+	// LocalReferences is every use of an identifier that's declared in this
+	// same file, in the order they appear. Uses of identifiers that are
+	// declared in other files are in the edges instead.
+	LocalReferences []Reference `json:"localReferences"`
+}
+
+type Edge struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Kind LinkKind `json:"kind"`
+	// References is every use in the from file of an identifier that's
+	// defined in the to file, in the order they appear.
+	References []Reference `json:"references"`
+}
+
+// Weight is the number of references that make up this edge.
+func (e Edge) Weight() int {
+	return len(e.References)
+}
+
+// Reference is a single use of an identifier.
+type Reference struct {
+	Identifier string `json:"identifier"`
+	Line       int    `json:"line"`
+	Column     int    `json:"column"`
+}
+
+// ExternalModule returns the path of the module that an import in a file comes
+// from, if it's from a module outside of the file's own module. Imports from
+// the standard library aren't considered external. If the import isn't from
+// any of the modules that are required in the go.mod file, then the import
+// path itself is returned.
+func (a *Analysis) ExternalModule(f File, importPath string) (string, bool) {
+	if f.Module != "" && (importPath == f.Module || strings.HasPrefix(importPath, f.Module+"/")) {
+		return "", false
+	}
+
+	// Standard library packages don't have a dot in their first path element,
+	// while everything else needs to start with a domain name.
+	if !strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".") {
+		return "", false
+	}
+
+	module := importPath
+	matched := ""
+	for _, m := range a.Modules {
+		if m.Path != f.Module {
+			continue
+		}
+		for _, r := range m.Requires {
+			if (importPath == r || strings.HasPrefix(importPath, r+"/")) && len(r) > len(matched) {
+				matched = r
+			}
+		}
+	}
+	if matched != "" {
+		module = matched
+	}
+	return module, true
+}
+
+// Edge returns the edge from one file to another, if there's a link between
+// them.
+func (a *Analysis) Edge(from, to string) (Edge, bool) {
+	i := sort.Search(len(a.Edges), func(i int) bool {
+		if a.Edges[i].From == from {
+			return a.Edges[i].To >= to
+		}
+		return a.Edges[i].From > from
+	})
+	if i < len(a.Edges) && a.Edges[i].From == from && a.Edges[i].To == to {
+		return a.Edges[i], true
+	}
+	return Edge{}, false
+}
+
+// Links returns the edges as a plain list of links.
+func (a *Analysis) Links() []Link {
+	links := make([]Link, 0, len(a.Edges))
+	for _, e := range a.Edges {
+		links = append(links, Link{From: e.From, To: e.To})
+	}
+	return links
+}
+
+// Entrypoints returns the names of the files that can be used from outside of
+// the analyzed directory: the files in main packages, the test files, and the
+// files that declare the exported API of library packages. Packages under an
+// internal directory aren't libraries since they can't be imported from
+// other modules.
+func (a *Analysis) Entrypoints() []string {
+	entrypoints := []string{}
+	for _, f := range a.Files {
+		if f.Test || f.PackageName == "main" || (!isInternal(f.Name) && declaresAPI(f)) {
+			entrypoints = append(entrypoints, f.Name)
+		}
+	}
+	return entrypoints
+}
+
+// isInternal returns whether a file is in an internal package.
+func isInternal(filename string) bool {
+	for _, dir := range strings.Split(path.Dir(filename), "/") {
+		if dir == "internal" {
+			return true
+		}
+	}
+	return false
+}
+
+// declaresAPI returns whether a file declares any exported identifier that's
+// reachable from outside of its package. Exported methods only count if their
+// receiver type is exported as well.
+func declaresAPI(f File) bool {
+	for _, s := range f.Symbols {
+		if !token.IsExported(s.Name) {
+			continue
+		}
+		if s.Kind != SymbolKindMethod || token.IsExported(s.Receiver) {
+			return true
+		}
+	}
+	return false
+}
+
+// analysisBuilder collects the files and references as the ASTs are walked,
+// and then turns them into a sorted Analysis.
+type analysisBuilder struct {
+	absRoot string
+	files   map[Filename]*File
+	edges   map[[2]Filename]*Edge
+	modules map[string]Module
+	// diagnostics is in the order the problems were found.
+	diagnostics []Diagnostic
+}
+
+func newAnalysisBuilder(absRoot string) *analysisBuilder {
+	return &analysisBuilder{
+		absRoot: absRoot,
+		files:   map[Filename]*File{},
+		edges:   map[[2]Filename]*Edge{},
+		modules: map[string]Module{},
+	}
+}
+
+func (b *analysisBuilder) relative(filename Filename) string {
+	return strings.Replace(string(filename), b.absRoot+"/", "", -1)
+}
+
+func (b *analysisBuilder) addSyntaxError(pos token.Position, message string) {
+	b.diagnostics = append(b.diagnostics, Diagnostic{
+		File:    b.relative(Filename(pos.Filename)),
+		Line:    pos.Line,
+		Column:  pos.Column,
+		Message: message,
+	})
+}
+
+func (b *analysisBuilder) addFile(filename Filename, pkgPath PackagePath, parsedDir *parser.ParsedDir, file *ast.File) {
+	imports := make([]string, 0, len(file.Imports))
+	for _, importSpec := range file.Imports {
+		imports = append(imports, strings.Trim(importSpec.Path.Value, "\""))
+	}
+
+	b.files[filename] = &File{
+		Name:            b.relative(filename),
+		Package:         string(pkgPath),
+		PackageName:     file.Name.Name,
+		Module:          parsedDir.ModulePath,
+		Test:            strings.HasSuffix(string(filename), "_test.go"),
+		Lines:           parsedDir.FileSet.File(file.Pos()).LineCount(),
+		Imports:         imports,
+		Symbols:         fileSymbols(parsedDir.FileSet, file),
+		LocalReferences: []Reference{},
+	}
+	b.modules[parsedDir.ModulePath] = Module{
+		Path:     parsedDir.ModulePath,
+		Requires: parsedDir.Requires,
+	}
+}
+
+// addReference records that identifier, used at pos in the from file, is
+// defined in the to file. A package can be imported both with "." and with a
+// name, in which case the edge is a dot-import whatever order the references
+// are found in.
+func (b *analysisBuilder) addReference(from, to Filename, kind LinkKind, identifier Identifier, pos token.Position) {
+	key := [2]Filename{from, to}
+	e, ok := b.edges[key]
+	if !ok {
+		e = &Edge{
+			From: b.relative(from),
+			To:   b.relative(to),
+			Kind: kind,
+		}
+		b.edges[key] = e
+	}
+	if kind == LinkKindDotImport {
+		e.Kind = kind
+	}
+	e.References = append(e.References, Reference{
+		Identifier: string(identifier),
+		Line:       pos.Line,
+		Column:     pos.Column,
+	})
+}
+
+func (b *analysisBuilder) addLocalReference(filename Filename, identifier Identifier, pos token.Position) {
+	f := b.files[filename]
+	f.LocalReferences = append(f.LocalReferences, Reference{
+		Identifier: string(identifier),
+		Line:       pos.Line,
+		Column:     pos.Column,
+	})
+}
+
+// doneFile doesn't need to do anything since the whole analysis is built at
+// the end.
+func (b *analysisBuilder) doneFile(filename Filename) error {
+	return nil
+}
+
+func (b *analysisBuilder) build() *Analysis {
+	a := &Analysis{
+		Files:       make([]File, 0, len(b.files)),
+		Edges:       make([]Edge, 0, len(b.edges)),
+		Modules:     make([]Module, 0, len(b.modules)),
+		Diagnostics: append([]Diagnostic{}, b.diagnostics...),
+	}
+	for _, f := range b.files {
+		a.Files = append(a.Files, *f)
+	}
+	for _, e := range b.edges {
+		// References are collected in two different walks over the AST, so
+		// they need to be put back in the order they appear in the file.
+		sort.SliceStable(e.References, func(i, j int) bool {
+			if e.References[i].Line == e.References[j].Line {
+				return e.References[i].Column < e.References[j].Column
+			}
+			return e.References[i].Line < e.References[j].Line
+		})
+		a.Edges = append(a.Edges, *e)
+	}
+
+	for _, m := range b.modules {
+		a.Modules = append(a.Modules, m)
+	}
+
+	// Sort the slices since map order isn't deterministic. While they don't
+	// need to be sorted, it helps if they are. And it's probably faster to
+	// sort them here than to do it downstream.
+	sort.Slice(a.Files, func(i, j int) bool {
+		return a.Files[i].Name < a.Files[j].Name
+	})
+	sort.Slice(a.Edges, func(i, j int) bool {
+		if a.Edges[i].From == a.Edges[j].From {
+			return a.Edges[i].To < a.Edges[j].To
+		}
+		return a.Edges[i].From < a.Edges[j].From
+	})
+	sort.Slice(a.Modules, func(i, j int) bool {
+		return a.Modules[i].Path < a.Modules[j].Path
+	})
+	sort.SliceStable(a.Diagnostics, func(i, j int) bool {
+		if a.Diagnostics[i].File != a.Diagnostics[j].File {
+			return a.Diagnostics[i].File < a.Diagnostics[j].File
+		}
+		if a.Diagnostics[i].Line != a.Diagnostics[j].Line {
+			return a.Diagnostics[i].Line < a.Diagnostics[j].Line
+		}
+		return a.Diagnostics[i].Column < a.Diagnostics[j].Column
+	})
+
+	return a
+}
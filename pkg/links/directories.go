@@ -1,6 +1,8 @@
 package links
 
 import (
+	"io/fs"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -8,7 +10,14 @@ import (
 	"github.com/pkg/errors"
 )
 
-func determineGoDirectories(root string) ([]string, error) {
+// determineGoDirectories returns every directory under root that has Go files
+// in it. If fsys isn't nil, the directories are read from it instead of from
+// disk, and they're returned as if the root of fsys were root.
+func determineGoDirectories(root string, fsys fs.FS) ([]string, error) {
+	if fsys != nil {
+		return determineGoDirectoriesFS(root, fsys)
+	}
+
 	dirSet := map[string]struct{}{}
 
 	err := godirwalk.Walk(root, &godirwalk.Options{
@@ -57,3 +66,34 @@ func determineGoDirectories(root string) ([]string, error) {
 
 	return dirs, nil
 }
+
+func determineGoDirectoriesFS(root string, fsys fs.FS) ([]string, error) {
+	dirSet := map[string]struct{}{}
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// The same directories are skipped as when walking the disk.
+		if d.IsDir() && (d.Name() == ".git" || d.Name() == "vendor") {
+			return fs.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+
+		dirSet[filepath.Join(root, filepath.FromSlash(path.Dir(name)))] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	dirs := make([]string, 0, len(dirSet))
+	for dir := range dirSet {
+		dirs = append(dirs, dir)
+	}
+
+	return dirs, nil
+}
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"sort"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,7 +14,7 @@ func TestDetermineGoDirectories(t *testing.T) {
 	t.Run("handles a simple repo", func(tt *testing.T) {
 		root := filepath.Clean("../testdata/simple-repo")
 
-		dirs, err := determineGoDirectories(root)
+		dirs, err := determineGoDirectories(root, nil)
 		require.NoError(tt, err)
 
 		// Sort the slice since its order isn't deterministic.
@@ -28,4 +29,21 @@ func TestDetermineGoDirectories(t *testing.T) {
 			"../testdata/simple-repo/pkg/signals",
 		}, dirs)
 	})
+
+	t.Run("walks an fs.FS", func(tt *testing.T) {
+		fsys := fstest.MapFS{
+			"main.go":               {},
+			"pkg/server/server.go":  {},
+			"pkg/server/README.md":  {},
+			"docs/index.md":         {},
+			"vendor/dep/dep.go":     {},
+			".git/hooks/pre-commit": {},
+		}
+
+		dirs, err := determineGoDirectories("/repo", fsys)
+		require.NoError(tt, err)
+
+		sort.Strings(dirs)
+		assert.Equal(tt, []string{"/repo", "/repo/pkg/server"}, dirs)
+	})
 }
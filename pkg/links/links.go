@@ -1,10 +1,10 @@
 package links
 
 import (
-	"fmt"
 	"go/ast"
+	"go/token"
+	"io/fs"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	"github.com/Codesee-io/codesee-deps-go/pkg/parser"
@@ -30,21 +30,94 @@ type (
 )
 
 // DetermineLinks takes in a root directory and generates all the links between
-// the Go files in this directory, relative from this root directory. The order
-// of links is not guaranteed to be deterministic to make it faster. If you're
-// asserting equality for the links (e.g. in a test), make sure you sort it
-// before your assertion.
+// the Go files in this directory, relative from this root directory. The links
+// are sorted by their from and to filenames.
 func DetermineLinks(root string) ([]Link, error) {
+	a, err := Analyze(root)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return a.Links(), nil
+}
+
+// Analyze takes in a root directory and determines all the links between the Go
+// files in this directory like DetermineLinks, but it also keeps everything
+// else it learned along the way, like which package each file is in and which
+// identifiers every link comes from.
+func Analyze(root string) (*Analysis, error) {
+	return AnalyzeFS(root, nil)
+}
+
+// AnalyzeFS is like Analyze, but it reads the files from fsys instead of from
+// disk, as if the root of fsys were the root directory. This can be used to
+// analyze files that aren't checked out, e.g. from a git revision. The
+// filenames in the analysis are relative from the root in both cases.
+func AnalyzeFS(root string, fsys fs.FS) (*Analysis, error) {
 	absRoot, err := filepath.Abs(root)
-	dirs, err := determineGoDirectories(absRoot)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	linksSet := map[string]struct{}{}
-	links := []Link{}
+	b := newAnalysisBuilder(absRoot)
+	err = walk(absRoot, fsys, b, false)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return b.build(), nil
+}
+
+// StreamLinks takes in a root directory and determines the same links as
+// DetermineLinks, but instead of collecting and sorting all of them, it calls
+// fn with each link as soon as the file it's from has been fully analyzed.
+// This keeps memory bounded for very large projects, at the cost of parsing
+// every file twice. The links for a file are sorted, but the order of the files
+// isn't deterministic. If fn returns an error, streaming stops and that error
+// is returned.
+func StreamLinks(root string, fn func(Link) error) error {
+	return StreamLinksFS(root, nil, fn)
+}
+
+// StreamLinksFS is like StreamLinks, but it reads the files from fsys instead
+// of from disk, like AnalyzeFS.
+func StreamLinksFS(root string, fsys fs.FS, fn func(Link) error) error {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return walk(absRoot, fsys, newLinkStreamer(absRoot, fn), true)
+}
 
-	p := parser.New(absRoot)
+// collector receives everything that's found while walking the ASTs.
+type collector interface {
+	// addFile is called for every file during the first pass.
+	addFile(filename Filename, pkgPath PackagePath, parsedDir *parser.ParsedDir, file *ast.File)
+	// addSyntaxError is called during the first pass for every syntax error
+	// in a directory that's skipped because it couldn't be parsed.
+	addSyntaxError(pos token.Position, message string)
+	// addReference is called during the second pass for every use in the from
+	// file of an identifier that's defined in the to file.
+	addReference(from, to Filename, kind LinkKind, identifier Identifier, pos token.Position)
+	// addLocalReference is called during the second pass for every use of an
+	// identifier that's defined in the same file.
+	addLocalReference(filename Filename, identifier Identifier, pos token.Position)
+	// doneFile is called during the second pass once all the references in a
+	// file have been added.
+	doneFile(filename Filename) error
+}
+
+// walk goes through all the Go files in absRoot, or in fsys if it isn't nil,
+// and reports the files and references it finds to the collector. If forget is
+// true, parsed directories are dropped from the parser's cache as soon as a
+// pass is done with them, so only one directory's AST is kept in memory at a
+// time.
+func walk(absRoot string, fsys fs.FS, c collector, forget bool) error {
+	dirs, err := determineGoDirectories(absRoot, fsys)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	p := parser.NewFS(absRoot, fsys)
 
 	// We determine the links for a project by making 2 passes over the
 	// directories.
@@ -67,7 +140,13 @@ func DetermineLinks(root string) ([]Link, error) {
 	for _, dir := range dirs {
 		parsedDir, err := p.Parse(dir)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return errors.WithStack(err)
+		}
+		for _, syntaxError := range p.SyntaxErrors(dir) {
+			c.addSyntaxError(syntaxError.Pos, syntaxError.Msg)
+		}
+		if forget {
+			p.Forget(dir)
 		}
 		if parsedDir == nil {
 			// This package wasn't able to be parsed correctly, so we just skip
@@ -86,6 +165,7 @@ func DetermineLinks(root string) ([]Link, error) {
 			for _, file := range pkg.Files {
 				pos := parsedDir.FileSet.Position(file.Pos())
 				filename := Filename(pos.Filename)
+				c.addFile(filename, pkgPath, parsedDir, file)
 
 				// For each file, go through all the objects that are in the
 				// global scope (e.g. types, functions, const and var
@@ -101,25 +181,15 @@ func DetermineLinks(root string) ([]Link, error) {
 		}
 	}
 
-	// This is a mapping from filename to the package path and object name that
-	// is being used in that file. This is how we'll know exactly what is being
-	// used in the imported package. This is necessary to be able to map back to
-	// where a specific object is defined. So this will look something like
-	// this:
-	// {
-	//   "/root/codesee-deps-go/pkg/links/links.go": {
-	//     "github.com/Codesee-io/codesee-deps-go/pkg/parser": {
-	//       "New": {}
-	//     }
-	//   }
-	// }
-	filenameToIdentifierUsed := map[Filename]map[PackagePath]map[Identifier]struct{}{}
-
-	// This second pass populates filenameToIdentifierUsed.
+	// This second pass goes through every identifier that's used in each file
+	// and looks up where it's defined.
 	for _, dir := range dirs {
 		parsedDir, err := p.Parse(dir)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return errors.WithStack(err)
+		}
+		if forget {
+			p.Forget(dir)
 		}
 		if parsedDir == nil {
 			// This package wasn't able to be parsed correctly, so we just skip
@@ -196,17 +266,15 @@ func DetermineLinks(root string) ([]Link, error) {
 				packagePathsWithUnqualifiedIdentifiers := append([]PackagePath{pkgPath}, dotImports...)
 
 				for _, ident := range file.Unresolved {
-					for _, pkgPath := range packagePathsWithUnqualifiedIdentifiers {
-						if toFilename, ok := identifierToFilename[pkgPath][Identifier(ident.String())]; ok {
-							setKey := fmt.Sprintf("%s:%s", filename, toFilename)
-
-							if _, ok := linksSet[setKey]; !ok {
-								links = append(links, Link{
-									From: strings.Replace(string(filename), absRoot+"/", "", -1),
-									To:   strings.Replace(string(toFilename), absRoot+"/", "", -1),
-								})
-								linksSet[setKey] = struct{}{}
+					for i, unqualifiedPkgPath := range packagePathsWithUnqualifiedIdentifiers {
+						if toFilename, ok := identifierToFilename[unqualifiedPkgPath][Identifier(ident.String())]; ok {
+							// The first package path is always this file's own
+							// package, and the rest are the dot imports.
+							kind := LinkKindDotImport
+							if i == 0 {
+								kind = LinkKindPackage
 							}
+							c.addReference(filename, toFilename, kind, Identifier(ident.String()), parsedDir.FileSet.Position(ident.Pos()))
 						}
 					}
 				}
@@ -246,63 +314,56 @@ func DetermineLinks(root string) ([]Link, error) {
 						return true
 					}
 
-					if _, ok := filenameToIdentifierUsed[filename]; !ok {
-						filenameToIdentifierUsed[filename] = map[PackagePath]map[Identifier]struct{}{}
+					identifiersDefined, ok := identifierToFilename[usedPkgPath]
+					if !ok {
+						// We don't have the identifiers for this package path.
+						// This is probably an external dependency.
+						return true
 					}
-					if _, ok := filenameToIdentifierUsed[filename][usedPkgPath]; !ok {
-						filenameToIdentifierUsed[filename][usedPkgPath] = map[Identifier]struct{}{}
+
+					toFilename, ok := identifiersDefined[usedIdentifier]
+					if !ok {
+						// We found an identifier being used by this package,
+						// but that identifier isn't defined in this package.
+						// This could be a Go file that wouldn't compile, or it
+						// could mean that we missed adding it. Either way, we
+						// don't want it interfering with all the other links,
+						// so we just skip it.
+						return true
 					}
-					filenameToIdentifierUsed[filename][usedPkgPath][usedIdentifier] = struct{}{}
+
+					c.addReference(filename, toFilename, LinkKindImport, usedIdentifier, parsedDir.FileSet.Position(selectorExpr.Sel.Pos()))
 
 					return true
 				})
-			}
-		}
-	}
-
-	// Now that we've pulled all the necessary data out of all the Go ASTs, we
-	// can piece together a comprehensive list of links from file to file.
-	for fromFilename, pkgPathsUsed := range filenameToIdentifierUsed {
-		for pkgPath, identifiersUsed := range pkgPathsUsed {
-			identifiersDefined, ok := identifierToFilename[pkgPath]
-			if !ok {
-				// We don't have the identifiers for this package path. This is
-				// probably an external dependency.
-				continue
-			}
 
-			for identifierUsed := range identifiersUsed {
-				toFilename, ok := identifiersDefined[identifierUsed]
-				if !ok {
-					// We found an identifier being used by this package, but
-					// that identifier isn't defined in this package. This could
-					// be a Go file that wouldn't compile, or it could mean that
-					// we missed adding it. Either way, we don't want it
-					// interfering with all the other links, so we just skip it.
-					continue
+				// Uses of identifiers that are defined in the same file don't
+				// make links, but they're still useful for things like code
+				// navigation. These are identifiers that refer to an object in
+				// the file's scope, other than the declarations themselves.
+				declared := map[token.Pos]struct{}{}
+				for _, ident := range declaredIdents(file) {
+					declared[ident.Pos()] = struct{}{}
 				}
-				setKey := fmt.Sprintf("%s:%s", fromFilename, toFilename)
-
-				if _, ok := linksSet[setKey]; !ok {
-					links = append(links, Link{
-						From: strings.Replace(string(fromFilename), absRoot+"/", "", -1),
-						To:   strings.Replace(string(toFilename), absRoot+"/", "", -1),
-					})
-					linksSet[setKey] = struct{}{}
+				ast.Inspect(file, func(n ast.Node) bool {
+					ident, ok := n.(*ast.Ident)
+					if !ok || !isScopeIdent(file, ident) {
+						return true
+					}
+					if _, ok := declared[ident.Pos()]; ok {
+						return true
+					}
+					c.addLocalReference(filename, Identifier(ident.Name), parsedDir.FileSet.Position(ident.Pos()))
+					return true
+				})
+
+				err := c.doneFile(filename)
+				if err != nil {
+					return errors.WithStack(err)
 				}
 			}
 		}
 	}
 
-	// Sort the slice since its order isn't deterministic. While it doesn't need
-	// to be sorted, it helps if it is. And it's probably faster to sort it here
-	// than to do it downstream.
-	sort.Slice(links, func(i, j int) bool {
-		if links[i].From == links[j].From {
-			return links[i].To < links[j].To
-		}
-		return links[i].From < links[j].From
-	})
-
-	return links, nil
+	return nil
 }
@@ -1,6 +1,8 @@
 package links
 
 import (
+	"go/token"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,3 +24,209 @@ func TestDetermineLinks(t *testing.T) {
 		}, links)
 	})
 }
+
+func TestAnalyzeFS(t *testing.T) {
+	t.Run("analyzes the files as if they were in the root", func(tt *testing.T) {
+		a, err := AnalyzeFS("/virtual/simple-repo", os.DirFS("../testdata/simple-repo"))
+		require.NoError(tt, err)
+
+		expected, err := Analyze("../testdata/simple-repo")
+		require.NoError(tt, err)
+
+		assert.Equal(tt, expected, a)
+	})
+}
+
+func TestAnalyze(t *testing.T) {
+	t.Run("keeps the files and references for a simple repo", func(tt *testing.T) {
+		root := "../testdata/simple-repo"
+
+		a, err := Analyze(root)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, []File{
+			{
+				Name:        "cmd/api/main.go",
+				Package:     "simple-repo/cmd/api",
+				PackageName: "main",
+				Module:      "simple-repo",
+				Lines:       40,
+				Imports:     []string{"context", "log", "net/http", "simple-repo/pkg/server", "simple-repo/pkg/signals"},
+				Symbols: []Symbol{
+					{Name: "port", Kind: SymbolKindConst, Line: 12, Column: 7},
+					{Name: "main", Kind: SymbolKindFunc, Line: 14, Column: 6},
+				},
+				LocalReferences: []Reference{
+					{Identifier: "port", Line: 15, Column: 25},
+					{Identifier: "port", Line: 23, Column: 45},
+				},
+			},
+			{
+				Name:            "pkg/handlers/handlers.go",
+				Package:         "simple-repo/pkg/handlers",
+				PackageName:     "handlers",
+				Module:          "simple-repo",
+				Lines:           10,
+				Imports:         []string{"fmt", "net/http"},
+				Symbols:         []Symbol{{Name: "Handler", Kind: SymbolKindFunc, Line: 8, Column: 6}},
+				LocalReferences: []Reference{},
+			},
+			{
+				Name:            "pkg/server/server.go",
+				Package:         "simple-repo/pkg/server",
+				PackageName:     "server",
+				Module:          "simple-repo",
+				Lines:           17,
+				Imports:         []string{"fmt", "net/http", "simple-repo/pkg/handlers"},
+				Symbols:         []Symbol{{Name: "New", Kind: SymbolKindFunc, Line: 10, Column: 6}},
+				LocalReferences: []Reference{},
+			},
+			{
+				Name:            "pkg/signals/signals.go",
+				Package:         "simple-repo/pkg/signals",
+				PackageName:     "signals",
+				Module:          "simple-repo",
+				Lines:           24,
+				Imports:         []string{"os", "os/signal", "syscall"},
+				Symbols:         []Symbol{{Name: "SetupSignals", Kind: SymbolKindFunc, Line: 12, Column: 6}},
+				LocalReferences: []Reference{},
+			},
+			{
+				Name:            "pkg/signals/signals_test.go",
+				Package:         "simple-repo/pkg/signals",
+				PackageName:     "signals",
+				Module:          "simple-repo",
+				Test:            true,
+				Lines:           22,
+				Imports:         []string{"syscall", "testing", "time", "github.com/stretchr/testify/require"},
+				Symbols:         []Symbol{{Name: "TestSetup", Kind: SymbolKindFunc, Line: 11, Column: 6}},
+				LocalReferences: []Reference{},
+			},
+		}, a.Files)
+
+		assert.Equal(tt, []Edge{
+			{
+				From:       "cmd/api/main.go",
+				To:         "pkg/server/server.go",
+				Kind:       LinkKindImport,
+				References: []Reference{{Identifier: "New", Line: 15, Column: 21}},
+			},
+			{
+				From:       "cmd/api/main.go",
+				To:         "pkg/signals/signals.go",
+				Kind:       LinkKindDotImport,
+				References: []Reference{{Identifier: "SetupSignals", Line: 20, Column: 14}},
+			},
+			{
+				From:       "pkg/server/server.go",
+				To:         "pkg/handlers/handlers.go",
+				Kind:       LinkKindImport,
+				References: []Reference{{Identifier: "Handler", Line: 13, Column: 31}},
+			},
+			{
+				From:       "pkg/signals/signals_test.go",
+				To:         "pkg/signals/signals.go",
+				Kind:       LinkKindPackage,
+				References: []Reference{{Identifier: "SetupSignals", Line: 12, Column: 8}},
+			},
+		}, a.Edges)
+
+		assert.Equal(tt, []Module{
+			{Path: "simple-repo", Requires: []string{"github.com/stretchr/testify"}},
+		}, a.Modules)
+
+		assert.Equal(tt, []Diagnostic{
+			{File: "pkg/invalid/invalid.go", Line: 3, Column: 1, Message: "expected declaration, found This"},
+		}, a.Diagnostics)
+	})
+}
+
+func TestAnalysisBuilder_addReference(t *testing.T) {
+	t.Run("prefers a dot import whatever the order of the references", func(tt *testing.T) {
+		for _, kinds := range [][]LinkKind{
+			{LinkKindImport, LinkKindDotImport},
+			{LinkKindDotImport, LinkKindImport},
+		} {
+			b := newAnalysisBuilder("/root")
+			for _, kind := range kinds {
+				b.addReference("/root/a.go", "/root/b.go", kind, "X", token.Position{Line: 1, Column: 1})
+			}
+			assert.Equal(tt, LinkKindDotImport, b.edges[[2]Filename{"/root/a.go", "/root/b.go"}].Kind)
+		}
+	})
+}
+
+func TestAnalysis_ExternalModule(t *testing.T) {
+	a := &Analysis{
+		Modules: []Module{
+			{Path: "example.com/app", Requires: []string{"github.com/pkg/errors", "golang.org/x/mod", "golang.org/x/mod/sub"}},
+		},
+	}
+	f := File{Name: "main.go", Module: "example.com/app"}
+
+	t.Run("ignores imports from the same module", func(tt *testing.T) {
+		_, ok := a.ExternalModule(f, "example.com/app/pkg/server")
+		assert.False(tt, ok)
+	})
+
+	t.Run("ignores the standard library", func(tt *testing.T) {
+		_, ok := a.ExternalModule(f, "net/http")
+		assert.False(tt, ok)
+	})
+
+	t.Run("finds the longest required module", func(tt *testing.T) {
+		module, ok := a.ExternalModule(f, "golang.org/x/mod/sub/pkg")
+		assert.True(tt, ok)
+		assert.Equal(tt, "golang.org/x/mod/sub", module)
+	})
+
+	t.Run("falls back to the import path", func(tt *testing.T) {
+		module, ok := a.ExternalModule(f, "github.com/other/thing")
+		assert.True(tt, ok)
+		assert.Equal(tt, "github.com/other/thing", module)
+	})
+}
+
+func TestAnalysis_Edge(t *testing.T) {
+	a := &Analysis{
+		Edges: []Edge{
+			{From: "a.go", To: "b.go", Kind: LinkKindPackage},
+			{From: "a.go", To: "c.go", Kind: LinkKindImport},
+			{From: "b.go", To: "a.go", Kind: LinkKindPackage},
+		},
+	}
+
+	t.Run("finds an edge", func(tt *testing.T) {
+		e, ok := a.Edge("a.go", "c.go")
+		assert.True(tt, ok)
+		assert.Equal(tt, LinkKindImport, e.Kind)
+	})
+
+	t.Run("returns false without a link", func(tt *testing.T) {
+		_, ok := a.Edge("c.go", "a.go")
+		assert.False(tt, ok)
+	})
+}
+
+func TestAnalysis_Entrypoints(t *testing.T) {
+	t.Run("returns the commands, tests and exported API", func(tt *testing.T) {
+		a := &Analysis{
+			Files: []File{
+				{Name: "cmd/api/main.go", PackageName: "main"},
+				{Name: "internal/db/db.go", PackageName: "db", Symbols: []Symbol{{Name: "Open", Kind: SymbolKindFunc}}},
+				{Name: "pkg/server/helpers.go", PackageName: "server", Symbols: []Symbol{
+					{Name: "helper", Kind: SymbolKindFunc},
+					{Name: "Close", Kind: SymbolKindMethod, Receiver: "conn"},
+				}},
+				{Name: "pkg/server/server.go", PackageName: "server", Symbols: []Symbol{{Name: "New", Kind: SymbolKindFunc}}},
+				{Name: "pkg/server/server_test.go", PackageName: "server", Test: true},
+			},
+		}
+
+		assert.Equal(tt, []string{
+			"cmd/api/main.go",
+			"pkg/server/server.go",
+			"pkg/server/server_test.go",
+		}, a.Entrypoints())
+	})
+}
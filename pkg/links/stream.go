@@ -0,0 +1,66 @@
+package links
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/parser"
+)
+
+// linkStreamer is a collector that only keeps track of the links for the file
+// that's currently being walked, and sends them to fn once that file is done.
+type linkStreamer struct {
+	absRoot string
+	fn      func(Link) error
+	// to is the set of files that the current file links to.
+	to map[Filename]struct{}
+}
+
+func newLinkStreamer(absRoot string, fn func(Link) error) *linkStreamer {
+	return &linkStreamer{
+		absRoot: absRoot,
+		fn:      fn,
+		to:      map[Filename]struct{}{},
+	}
+}
+
+func (s *linkStreamer) relative(filename Filename) string {
+	return strings.Replace(string(filename), s.absRoot+"/", "", -1)
+}
+
+func (s *linkStreamer) addFile(filename Filename, pkgPath PackagePath, parsedDir *parser.ParsedDir, file *ast.File) {
+}
+
+func (s *linkStreamer) addSyntaxError(pos token.Position, message string) {
+}
+
+func (s *linkStreamer) addReference(from, to Filename, kind LinkKind, identifier Identifier, pos token.Position) {
+	s.to[to] = struct{}{}
+}
+
+func (s *linkStreamer) addLocalReference(filename Filename, identifier Identifier, pos token.Position) {
+}
+
+func (s *linkStreamer) doneFile(filename Filename) error {
+	if len(s.to) == 0 {
+		return nil
+	}
+
+	from := s.relative(filename)
+	to := make([]string, 0, len(s.to))
+	for toFilename := range s.to {
+		to = append(to, s.relative(toFilename))
+	}
+	sort.Strings(to)
+	s.to = map[Filename]struct{}{}
+
+	for _, t := range to {
+		err := s.fn(Link{From: from, To: t})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
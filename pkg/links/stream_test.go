@@ -0,0 +1,48 @@
+package links
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamLinks(t *testing.T) {
+	t.Run("streams the same links as DetermineLinks", func(tt *testing.T) {
+		root := "../testdata/simple-repo"
+
+		streamed := []Link{}
+		err := StreamLinks(root, func(l Link) error {
+			streamed = append(streamed, l)
+			return nil
+		})
+		require.NoError(tt, err)
+
+		expected, err := DetermineLinks(root)
+		require.NoError(tt, err)
+
+		// Sort the slice since the order of the files isn't deterministic.
+		sort.Slice(streamed, func(i, j int) bool {
+			if streamed[i].From == streamed[j].From {
+				return streamed[i].To < streamed[j].To
+			}
+			return streamed[i].From < streamed[j].From
+		})
+		assert.Equal(tt, expected, streamed)
+	})
+
+	t.Run("stops when the callback returns an error", func(tt *testing.T) {
+		root := "../testdata/simple-repo"
+		stop := errors.New("stop")
+
+		count := 0
+		err := StreamLinks(root, func(l Link) error {
+			count++
+			return stop
+		})
+		assert.True(tt, errors.Is(err, stop))
+		assert.Equal(tt, 1, count)
+	})
+}
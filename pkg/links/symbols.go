@@ -0,0 +1,152 @@
+package links
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// SymbolKind is the kind of declaration that a symbol comes from.
+type SymbolKind string
+
+const (
+	SymbolKindFunc      SymbolKind = "func"
+	SymbolKindMethod    SymbolKind = "method"
+	SymbolKindStruct    SymbolKind = "struct"
+	SymbolKindInterface SymbolKind = "interface"
+	// SymbolKindType is any other type declaration, like a named slice or map,
+	// or a type alias.
+	SymbolKindType  SymbolKind = "type"
+	SymbolKindConst SymbolKind = "const"
+	SymbolKindVar   SymbolKind = "var"
+)
+
+// Symbol is an identifier that's declared at the top level of a file, like a
+// type, function, method, const or var.
+type Symbol struct {
+	Name string     `json:"name"`
+	Kind SymbolKind `json:"kind"`
+	// Receiver is the name of the receiver's type for methods, without the
+	// pointer. It's empty for everything else.
+	Receiver string `json:"receiver,omitempty"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// declaredIdents returns the identifiers that are declared in the file's scope,
+// in the order that they're declared. These are the same identifiers that can
+// be used by other files in the package.
+func declaredIdents(file *ast.File) []*ast.Ident {
+	idents := []*ast.Ident{}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			idents = append(idents, d.Name)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					idents = append(idents, s.Name)
+				case *ast.ValueSpec:
+					idents = append(idents, s.Names...)
+				}
+			}
+		}
+	}
+
+	// Methods, init functions and blank identifiers aren't added to the
+	// file's scope, so they're filtered out here.
+	inScope := make([]*ast.Ident, 0, len(idents))
+	for _, ident := range idents {
+		if isScopeIdent(file, ident) {
+			inScope = append(inScope, ident)
+		}
+	}
+	return inScope
+}
+
+// isScopeIdent returns whether the identifier refers to an object that's in
+// the file's scope.
+func isScopeIdent(file *ast.File, ident *ast.Ident) bool {
+	return ident.Obj != nil && file.Scope.Objects[ident.Name] == ident.Obj
+}
+
+// fileSymbols returns all the symbols declared at the top level of the file in
+// the order they're declared. Unlike declaredIdents, this includes methods.
+func fileSymbols(fset *token.FileSet, file *ast.File) []Symbol {
+	symbols := []Symbol{}
+	add := func(ident *ast.Ident, kind SymbolKind, receiver string) {
+		if ident.Name == "_" || (kind == SymbolKindFunc && ident.Name == "init") {
+			return
+		}
+		pos := fset.Position(ident.Pos())
+		symbols = append(symbols, Symbol{
+			Name:     ident.Name,
+			Kind:     kind,
+			Receiver: receiver,
+			Line:     pos.Line,
+			Column:   pos.Column,
+		})
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				add(d.Name, SymbolKindMethod, receiverName(d.Recv.List[0].Type))
+			} else {
+				add(d.Name, SymbolKindFunc, "")
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add(s.Name, typeKind(s), "")
+				case *ast.ValueSpec:
+					kind := SymbolKindVar
+					if d.Tok == token.CONST {
+						kind = SymbolKindConst
+					}
+					for _, name := range s.Names {
+						add(name, kind, "")
+					}
+				}
+			}
+		}
+	}
+
+	return symbols
+}
+
+func typeKind(s *ast.TypeSpec) SymbolKind {
+	if s.Assign.IsValid() {
+		// Aliases are always the kind type, even if they're an alias of a
+		// struct or interface.
+		return SymbolKindType
+	}
+	switch s.Type.(type) {
+	case *ast.StructType:
+		return SymbolKindStruct
+	case *ast.InterfaceType:
+		return SymbolKindInterface
+	default:
+		return SymbolKindType
+	}
+}
+
+// receiverName returns the name of the receiver's type, e.g. Parser for both
+// (p Parser) and (p *Parser).
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.ParenExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		// This is a generic type, e.g. (l *List[T]).
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	default:
+		return ""
+	}
+}
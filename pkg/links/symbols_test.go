@@ -0,0 +1,52 @@
+package links
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSymbols(t *testing.T) {
+	t.Run("includes every top-level declaration and method", func(tt *testing.T) {
+		src := `package example
+
+type T struct{}
+
+type I interface{}
+
+type A = T
+
+type S []string
+
+func (t *T) Method() {}
+
+func init() {}
+
+var a, _, b = 1, 2, 3
+
+const (
+	C = iota
+)
+
+func F() {}
+`
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "example.go", src, 0)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, []Symbol{
+			{Name: "T", Kind: SymbolKindStruct, Line: 3, Column: 6},
+			{Name: "I", Kind: SymbolKindInterface, Line: 5, Column: 6},
+			{Name: "A", Kind: SymbolKindType, Line: 7, Column: 6},
+			{Name: "S", Kind: SymbolKindType, Line: 9, Column: 6},
+			{Name: "Method", Kind: SymbolKindMethod, Receiver: "T", Line: 11, Column: 13},
+			{Name: "a", Kind: SymbolKindVar, Line: 15, Column: 5},
+			{Name: "b", Kind: SymbolKindVar, Line: 15, Column: 11},
+			{Name: "C", Kind: SymbolKindConst, Line: 18, Column: 2},
+			{Name: "F", Kind: SymbolKindFunc, Line: 21, Column: 6},
+		}, fileSymbols(fset, file))
+	})
+}
@@ -0,0 +1,45 @@
+package output
+
+import (
+	"io/fs"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// utf16Columns converts Go's byte columns to the UTF-16 offsets that editors
+// and code review tools count in. It reads every file at most once.
+type utf16Columns struct {
+	fsys fs.FS
+	// lines caches the lines of every file that's been read.
+	lines map[string][]string
+}
+
+func newUTF16Columns(fsys fs.FS) *utf16Columns {
+	return &utf16Columns{
+		fsys:  fsys,
+		lines: map[string][]string{},
+	}
+}
+
+// offset converts a 1-based byte column in a file to a 0-based UTF-16 offset.
+// The filename is relative from the root of fsys. If the file can't be read,
+// the error is returned along with the byte offset, which is the same as long
+// as the line is ASCII.
+func (c *utf16Columns) offset(filename string, line, column int) (int, error) {
+	lines, ok := c.lines[filename]
+	var err error
+	if !ok {
+		var src []byte
+		src, err = fs.ReadFile(c.fsys, filename)
+		err = errors.WithStack(err)
+		lines = strings.Split(string(src), "\n")
+		c.lines[filename] = lines
+	}
+
+	if line < 1 || line > len(lines) || column-1 > len(lines[line-1]) {
+		return column - 1, err
+	}
+	return len(utf16.Encode([]rune(lines[line-1][:column-1]))), err
+}
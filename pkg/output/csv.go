@@ -0,0 +1,62 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/pkg/errors"
+)
+
+// CSVColumns are the optional columns that can be added after the from and to
+// columns of the CSV output.
+var CSVColumns = []string{"weight", "kind"}
+
+// CSV writes the edges of the graph as comma-separated values with a header
+// row. The from and to columns are always written, followed by any of the
+// optional CSVColumns. Use a tab for the separator to get TSV instead.
+func CSV(w io.Writer, g *graph.Graph, separator rune, columns []string) error {
+	for _, column := range columns {
+		if !isCSVColumn(column) {
+			return errors.Errorf("unknown column %q", column)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = separator
+
+	err := cw.Write(append([]string{"from", "to"}, columns...))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, e := range g.Edges {
+		record := []string{e.From, e.To}
+		for _, column := range columns {
+			switch column {
+			case "weight":
+				record = append(record, strconv.Itoa(e.Weight))
+			case "kind":
+				record = append(record, string(e.Kind))
+			}
+		}
+
+		err := cw.Write(record)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	cw.Flush()
+	return errors.WithStack(cw.Error())
+}
+
+func isCSVColumn(column string) bool {
+	for _, c := range CSVColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
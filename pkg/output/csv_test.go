@@ -0,0 +1,37 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSV(t *testing.T) {
+	t.Run("writes the from and to columns with a header", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := CSV(&buf, testGraph, ',', nil)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, "from,to\n"+
+			"cmd/api/main.go,pkg/server/server.go\n"+
+			"pkg/server/server_test.go,pkg/server/server.go\n", buf.String())
+	})
+
+	t.Run("writes the optional columns as TSV", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := CSV(&buf, testGraph, '\t', []string{"kind", "weight"})
+		require.NoError(tt, err)
+
+		assert.Equal(tt, "from\tto\tkind\tweight\n"+
+			"cmd/api/main.go\tpkg/server/server.go\timport\t2\n"+
+			"pkg/server/server_test.go\tpkg/server/server.go\tpackage\t1\n", buf.String())
+	})
+
+	t.Run("returns an error for an unknown column", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := CSV(&buf, testGraph, ',', []string{"color"})
+		assert.EqualError(tt, err, `unknown column "color"`)
+	})
+}
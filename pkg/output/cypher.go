@@ -0,0 +1,80 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/pkg/errors"
+)
+
+// Cypher writes the analysis as a Neo4j Cypher script. It creates File,
+// Package and Module nodes, with IN_PACKAGE relationships from files to their
+// packages, IN_MODULE relationships from packages to their modules, and
+// DEPENDS_ON relationships between files. Everything uses MERGE, so running
+// the script again or loading several repositories into the same database
+// doesn't create duplicates. Files are identified by their module and their
+// path relative from the root, so that files from different repositories don't
+// collide.
+func Cypher(w io.Writer, a *links.Analysis) error {
+	var b strings.Builder
+
+	modules := map[string]struct{}{}
+	packages := map[string]string{}
+	fileModules := map[string]string{}
+	for _, f := range a.Files {
+		modules[f.Module] = struct{}{}
+		packages[f.Package] = f.Module
+		fileModules[f.Name] = f.Module
+	}
+
+	b.WriteString("// Modules\n")
+	for _, module := range sortedKeys(modules) {
+		fmt.Fprintf(&b, "MERGE (:Module {path: %s});\n", cypherString(module))
+	}
+
+	b.WriteString("\n// Packages\n")
+	pkgPaths := make([]string, 0, len(packages))
+	for pkgPath := range packages {
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+	sort.Strings(pkgPaths)
+	for _, pkgPath := range pkgPaths {
+		fmt.Fprintf(&b, "MERGE (p:Package {path: %s}) MERGE (m:Module {path: %s}) MERGE (p)-[:IN_MODULE]->(m);\n",
+			cypherString(pkgPath), cypherString(packages[pkgPath]))
+	}
+
+	b.WriteString("\n// Files\n")
+	for _, f := range a.Files {
+		fmt.Fprintf(&b, "MERGE (f:File {module: %s, path: %s}) SET f.test = %t, f.lines = %d MERGE (p:Package {path: %s}) MERGE (f)-[:IN_PACKAGE]->(p);\n",
+			cypherString(f.Module), cypherString(f.Name), f.Test, f.Lines, cypherString(f.Package))
+	}
+
+	b.WriteString("\n// Links\n")
+	for _, e := range a.Edges {
+		fmt.Fprintf(&b, "MATCH (a:File {module: %s, path: %s}), (b:File {module: %s, path: %s}) MERGE (a)-[r:DEPENDS_ON]->(b) SET r.weight = %d, r.kind = %s;\n",
+			cypherString(fileModules[e.From]), cypherString(e.From),
+			cypherString(fileModules[e.To]), cypherString(e.To),
+			e.Weight(), cypherString(string(e.Kind)))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return errors.WithStack(err)
+}
+
+func cypherString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `'`, `\'`, -1)
+	return "'" + s + "'"
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
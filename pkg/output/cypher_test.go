@@ -0,0 +1,52 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCypher(t *testing.T) {
+	t.Run("merges the nodes and relationships", func(tt *testing.T) {
+		a := &links.Analysis{
+			Files: []links.File{
+				{Name: "cmd/api/main.go", Package: "simple-repo/cmd/api", Module: "simple-repo", Lines: 40},
+				{Name: "pkg/server/server.go", Package: "simple-repo/pkg/server", Module: "simple-repo", Lines: 17},
+			},
+			Edges: []links.Edge{
+				{
+					From:       "cmd/api/main.go",
+					To:         "pkg/server/server.go",
+					Kind:       links.LinkKindImport,
+					References: []links.Reference{{Identifier: "New", Line: 15, Column: 21}},
+				},
+			},
+		}
+
+		var buf bytes.Buffer
+		err := Cypher(&buf, a)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, `// Modules
+MERGE (:Module {path: 'simple-repo'});
+
+// Packages
+MERGE (p:Package {path: 'simple-repo/cmd/api'}) MERGE (m:Module {path: 'simple-repo'}) MERGE (p)-[:IN_MODULE]->(m);
+MERGE (p:Package {path: 'simple-repo/pkg/server'}) MERGE (m:Module {path: 'simple-repo'}) MERGE (p)-[:IN_MODULE]->(m);
+
+// Files
+MERGE (f:File {module: 'simple-repo', path: 'cmd/api/main.go'}) SET f.test = false, f.lines = 40 MERGE (p:Package {path: 'simple-repo/cmd/api'}) MERGE (f)-[:IN_PACKAGE]->(p);
+MERGE (f:File {module: 'simple-repo', path: 'pkg/server/server.go'}) SET f.test = false, f.lines = 17 MERGE (p:Package {path: 'simple-repo/pkg/server'}) MERGE (f)-[:IN_PACKAGE]->(p);
+
+// Links
+MATCH (a:File {module: 'simple-repo', path: 'cmd/api/main.go'}), (b:File {module: 'simple-repo', path: 'pkg/server/server.go'}) MERGE (a)-[r:DEPENDS_ON]->(b) SET r.weight = 1, r.kind = 'import';
+`, buf.String())
+	})
+
+	t.Run("escapes quotes and backslashes", func(tt *testing.T) {
+		assert.Equal(tt, `'it\'s a \\ test'`, cypherString(`it's a \ test`))
+	})
+}
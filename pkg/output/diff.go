@@ -0,0 +1,97 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/pkg/errors"
+)
+
+// DiffText writes the differences between two lists of links as plain text,
+// leaving out the sections without any changes.
+func DiffText(w io.Writer, d graph.Diff) error {
+	var b strings.Builder
+
+	if len(d.Added) == 0 && len(d.Removed) == 0 && len(d.NewCycles) == 0 && len(d.FanChanges) == 0 {
+		b.WriteString("no changes\n")
+	}
+
+	if len(d.Added) > 0 {
+		fmt.Fprintf(&b, "added links (%d):\n", len(d.Added))
+		for _, l := range d.Added {
+			fmt.Fprintf(&b, "  + %s -> %s\n", l.From, l.To)
+		}
+	}
+	if len(d.Removed) > 0 {
+		fmt.Fprintf(&b, "removed links (%d):\n", len(d.Removed))
+		for _, l := range d.Removed {
+			fmt.Fprintf(&b, "  - %s -> %s\n", l.From, l.To)
+		}
+	}
+	if len(d.NewCycles) > 0 {
+		fmt.Fprintf(&b, "new cycles (%d):\n", len(d.NewCycles))
+		for _, c := range d.NewCycles {
+			fmt.Fprintf(&b, "  %s\n", strings.Join(c.Nodes, ", "))
+		}
+	}
+	if len(d.FanChanges) > 0 {
+		fmt.Fprintf(&b, "fan-in and fan-out changes (%d):\n", len(d.FanChanges))
+		for _, c := range d.FanChanges {
+			fmt.Fprintf(&b, "  %s: fan-in %d -> %d, fan-out %d -> %d\n", c.ID, c.FanIn[0], c.FanIn[1], c.FanOut[0], c.FanOut[1])
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return errors.WithStack(err)
+}
+
+// DiffMarkdown writes the differences between two lists of links as Markdown,
+// e.g. for a pull request comment.
+func DiffMarkdown(w io.Writer, d graph.Diff) error {
+	var b strings.Builder
+
+	b.WriteString("## Dependency changes\n\n")
+	fmt.Fprintf(&b, "%d links added, %d removed, %d new cycles, %d fan-in or fan-out changes.\n",
+		len(d.Added), len(d.Removed), len(d.NewCycles), len(d.FanChanges))
+
+	if len(d.Added) > 0 {
+		b.WriteString("\n### Added links\n\n| From | To |\n| --- | --- |\n")
+		for _, l := range d.Added {
+			fmt.Fprintf(&b, "| %s | %s |\n", markdownCode(l.From), markdownCode(l.To))
+		}
+	}
+	if len(d.Removed) > 0 {
+		b.WriteString("\n### Removed links\n\n| From | To |\n| --- | --- |\n")
+		for _, l := range d.Removed {
+			fmt.Fprintf(&b, "| %s | %s |\n", markdownCode(l.From), markdownCode(l.To))
+		}
+	}
+	if len(d.NewCycles) > 0 {
+		b.WriteString("\n### New cycles\n\n")
+		for _, c := range d.NewCycles {
+			nodes := make([]string, 0, len(c.Nodes))
+			for _, id := range c.Nodes {
+				nodes = append(nodes, markdownCode(id))
+			}
+			fmt.Fprintf(&b, "- %s\n", strings.Join(nodes, ", "))
+		}
+	}
+	if len(d.FanChanges) > 0 {
+		b.WriteString("\n### Fan-in and fan-out changes\n\n| File | Fan-in | Fan-out |\n| --- | --- | --- |\n")
+		for _, c := range d.FanChanges {
+			fmt.Fprintf(&b, "| %s | %d → %d | %d → %d |\n", markdownCode(c.ID), c.FanIn[0], c.FanIn[1], c.FanOut[0], c.FanOut[1])
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return errors.WithStack(err)
+}
+
+// markdownCode formats a filename as inline code. Filenames can't contain
+// backticks in any sensible project, so they aren't escaped, but pipes would
+// break the tables.
+func markdownCode(s string) string {
+	return "`" + strings.Replace(s, "|", "\\|", -1) + "`"
+}
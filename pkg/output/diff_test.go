@@ -0,0 +1,62 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testDiff = graph.Diff{
+	Added:   []links.Link{{From: "a.go", To: "b.go"}},
+	Removed: []links.Link{{From: "c.go", To: "d.go"}},
+	NewCycles: []graph.Cycle{
+		{Nodes: []string{"a.go", "b.go"}},
+	},
+	FanChanges: []graph.FanChange{
+		{ID: "b.go", FanIn: [2]int{1, 4}, FanOut: [2]int{2, 2}},
+	},
+}
+
+func TestDiffText(t *testing.T) {
+	t.Run("writes every section with changes", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := DiffText(&buf, testDiff)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, "added links (1):\n"+
+			"  + a.go -> b.go\n"+
+			"removed links (1):\n"+
+			"  - c.go -> d.go\n"+
+			"new cycles (1):\n"+
+			"  a.go, b.go\n"+
+			"fan-in and fan-out changes (1):\n"+
+			"  b.go: fan-in 1 -> 4, fan-out 2 -> 2\n", buf.String())
+	})
+
+	t.Run("says when nothing changed", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := DiffText(&buf, graph.Diff{})
+		require.NoError(tt, err)
+
+		assert.Equal(tt, "no changes\n", buf.String())
+	})
+}
+
+func TestDiffMarkdown(t *testing.T) {
+	t.Run("writes a summary and a table for every section", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := DiffMarkdown(&buf, testDiff)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, "## Dependency changes\n\n"+
+			"1 links added, 1 removed, 1 new cycles, 1 fan-in or fan-out changes.\n\n"+
+			"### Added links\n\n| From | To |\n| --- | --- |\n| `a.go` | `b.go` |\n\n"+
+			"### Removed links\n\n| From | To |\n| --- | --- |\n| `c.go` | `d.go` |\n\n"+
+			"### New cycles\n\n- `a.go`, `b.go`\n\n"+
+			"### Fan-in and fan-out changes\n\n| File | Fan-in | Fan-out |\n| --- | --- | --- |\n| `b.go` | 1 → 4 | 2 → 2 |\n", buf.String())
+	})
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/pkg/errors"
+)
+
+// DSM writes a dependency structure matrix as a text table for the terminal.
+// Every row is numbered, and the columns are labeled with the same numbers so
+// the table stays narrow. A cell is the number of references from the node of
+// its row to the node of its column, and the diagonal is marked with a dot.
+// Rows that are part of a cycle are marked with a *.
+func DSM(w io.Writer, m *graph.Matrix) error {
+	var b strings.Builder
+
+	indexWidth := len(strconv.Itoa(len(m.Nodes)))
+	nameWidth := 0
+	cellWidth := indexWidth
+	for i, id := range m.Nodes {
+		if len(id) > nameWidth {
+			nameWidth = len(id)
+		}
+		for _, cell := range m.Cells[i] {
+			if width := len(strconv.Itoa(cell)); width > cellWidth {
+				cellWidth = width
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "  %*s %-*s", indexWidth, "", nameWidth, "")
+	for j := range m.Nodes {
+		fmt.Fprintf(&b, " %*d", cellWidth, j+1)
+	}
+	b.WriteString("\n")
+
+	for i, id := range m.Nodes {
+		marker := " "
+		if m.Cyclic(i) {
+			marker = "*"
+		}
+		fmt.Fprintf(&b, "%s %*d %-*s", marker, indexWidth, i+1, nameWidth, id)
+		for j, cell := range m.Cells[i] {
+			value := ""
+			if i == j {
+				value = "."
+			} else if cell > 0 {
+				value = strconv.Itoa(cell)
+			}
+			fmt.Fprintf(&b, " %*s", cellWidth, value)
+		}
+		b.WriteString("\n")
+	}
+
+	// The padding of the last columns isn't needed for anything.
+	lines := strings.Split(b.String(), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n"))
+	return errors.WithStack(err)
+}
+
+// DSMCSV writes a dependency structure matrix as comma-separated values. The
+// header row and the first column are the node IDs, and the cells without a
+// dependency are left empty.
+func DSMCSV(w io.Writer, m *graph.Matrix) error {
+	cw := csv.NewWriter(w)
+
+	err := cw.Write(append([]string{""}, m.Nodes...))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for i, id := range m.Nodes {
+		record := []string{id}
+		for _, cell := range m.Cells[i] {
+			value := ""
+			if cell > 0 {
+				value = strconv.Itoa(cell)
+			}
+			record = append(record, value)
+		}
+
+		err := cw.Write(record)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	cw.Flush()
+	return errors.WithStack(cw.Error())
+}
@@ -0,0 +1,47 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMatrix = &graph.Matrix{
+	Nodes:      []string{"pkg/a", "pkg/b", "cmd/api"},
+	Components: []int{0, 0, 1},
+	Cells: [][]int{
+		{0, 2, 0},
+		{1, 0, 0},
+		{12, 0, 0},
+	},
+}
+
+func TestDSM(t *testing.T) {
+	t.Run("writes a numbered table and marks the cycles", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := DSM(&buf, testMatrix)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, ""+
+			"             1  2  3\n"+
+			"* 1 pkg/a    .  2\n"+
+			"* 2 pkg/b    1  .\n"+
+			"  3 cmd/api 12     .\n", buf.String())
+	})
+}
+
+func TestDSMCSV(t *testing.T) {
+	t.Run("writes the node IDs as the header and first column", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := DSMCSV(&buf, testMatrix)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, ",pkg/a,pkg/b,cmd/api\n"+
+			"pkg/a,,2,\n"+
+			"pkg/b,1,,\n"+
+			"cmd/api,12,,\n", buf.String())
+	})
+}
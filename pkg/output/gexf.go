@@ -0,0 +1,111 @@
+package output
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+)
+
+type gexf struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Mode            string           `xml:"mode,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Weight    int            `xml:"weight,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// GEXF writes the graph as a GEXF 1.3 document, which is the native format of
+// Gephi. The edge weight uses GEXF's built-in weight attribute.
+func GEXF(w io.Writer, g *graph.Graph) error {
+	doc := gexf{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Graph: gexfGraph{
+			DefaultEdgeType: "directed",
+			Mode:            "static",
+			Attributes: []gexfAttributes{
+				{
+					Class: "node",
+					Attributes: []gexfAttribute{
+						{ID: "package", Title: "package", Type: "string"},
+						{ID: "test", Title: "test", Type: "boolean"},
+						{ID: "lines", Title: "lines", Type: "integer"},
+					},
+				},
+				{
+					Class: "edge",
+					Attributes: []gexfAttribute{
+						{ID: "kind", Title: "kind", Type: "string"},
+					},
+				},
+			},
+			Nodes: make([]gexfNode, 0, len(g.Nodes)),
+			Edges: make([]gexfEdge, 0, len(g.Edges)),
+		},
+	}
+
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{
+			ID:    n.ID,
+			Label: n.ID,
+			AttValues: []gexfAttValue{
+				{For: "package", Value: n.Package},
+				{For: "test", Value: strconv.FormatBool(n.Test)},
+				{For: "lines", Value: strconv.Itoa(n.Lines)},
+			},
+		})
+	}
+
+	for i, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{
+			ID:     strconv.Itoa(i),
+			Source: e.From,
+			Target: e.To,
+			Weight: e.Weight,
+			AttValues: []gexfAttValue{
+				{For: "kind", Value: string(e.Kind)},
+			},
+		})
+	}
+
+	return writeXML(w, doc)
+}
@@ -0,0 +1,42 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGEXF(t *testing.T) {
+	t.Run("writes the nodes and edges with their attributes", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := GEXF(&buf, testGraph)
+		require.NoError(tt, err)
+
+		var doc gexf
+		err = xml.Unmarshal(buf.Bytes(), &doc)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, "1.3", doc.Version)
+		require.Len(tt, doc.Graph.Nodes, 3)
+		assert.Equal(tt, gexfNode{
+			ID:    "cmd/api/main.go",
+			Label: "cmd/api/main.go",
+			AttValues: []gexfAttValue{
+				{For: "package", Value: "simple-repo/cmd/api"},
+				{For: "test", Value: "false"},
+				{For: "lines", Value: "40"},
+			},
+		}, doc.Graph.Nodes[0])
+		require.Len(tt, doc.Graph.Edges, 2)
+		assert.Equal(tt, gexfEdge{
+			ID:        "1",
+			Source:    "pkg/server/server_test.go",
+			Target:    "pkg/server/server.go",
+			Weight:    1,
+			AttValues: []gexfAttValue{{For: "kind", Value: "package"}},
+		}, doc.Graph.Edges[1])
+	})
+}
@@ -0,0 +1,58 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testGraph = &graph.Graph{
+	Nodes: []graph.Node{
+		{ID: "cmd/api/main.go", Package: "simple-repo/cmd/api", Lines: 40},
+		{ID: "pkg/server/server.go", Package: "simple-repo/pkg/server", Lines: 17},
+		{ID: "pkg/server/server_test.go", Package: "simple-repo/pkg/server", Test: true, Lines: 12},
+	},
+	Edges: []graph.Edge{
+		{From: "cmd/api/main.go", To: "pkg/server/server.go", Weight: 2, Kind: links.LinkKindImport},
+		{From: "pkg/server/server_test.go", To: "pkg/server/server.go", Weight: 1, Kind: links.LinkKindPackage},
+	},
+}
+
+func TestGraphML(t *testing.T) {
+	t.Run("writes the nodes and edges with their attributes", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := GraphML(&buf, testGraph)
+		require.NoError(tt, err)
+
+		var doc graphML
+		err = xml.Unmarshal(buf.Bytes(), &doc)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, "directed", doc.Graph.EdgeDefault)
+		require.Len(tt, doc.Graph.Nodes, 3)
+		assert.Equal(tt, graphMLNode{
+			ID: "pkg/server/server_test.go",
+			Data: []graphMLData{
+				{Key: "label", Value: "pkg/server/server_test.go"},
+				{Key: "package", Value: "simple-repo/pkg/server"},
+				{Key: "test", Value: "true"},
+				{Key: "lines", Value: "12"},
+			},
+		}, doc.Graph.Nodes[2])
+		require.Len(tt, doc.Graph.Edges, 2)
+		assert.Equal(tt, graphMLEdge{
+			ID:     "e0",
+			Source: "cmd/api/main.go",
+			Target: "pkg/server/server.go",
+			Data: []graphMLData{
+				{Key: "weight", Value: "2"},
+				{Key: "kind", Value: "import"},
+			},
+		}, doc.Graph.Edges[0])
+	})
+}
@@ -0,0 +1,226 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"unicode/utf16"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/pkg/errors"
+)
+
+// lsifElement is a vertex or an edge in an LSIF dump. The two share a single
+// type since they're only ever written out, and the fields that aren't used by
+// a specific element are left empty.
+type lsifElement struct {
+	ID    int    `json:"id"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+
+	Version          string        `json:"version,omitempty"`
+	ProjectRoot      string        `json:"projectRoot,omitempty"`
+	PositionEncoding string        `json:"positionEncoding,omitempty"`
+	ToolInfo         *lsifToolInfo `json:"toolInfo,omitempty"`
+	Kind             string        `json:"kind,omitempty"`
+	URI              string        `json:"uri,omitempty"`
+	LanguageID       string        `json:"languageId,omitempty"`
+	Start            *lsifPosition `json:"start,omitempty"`
+	End              *lsifPosition `json:"end,omitempty"`
+
+	OutV     int    `json:"outV,omitempty"`
+	InV      int    `json:"inV,omitempty"`
+	InVs     []int  `json:"inVs,omitempty"`
+	Document int    `json:"document,omitempty"`
+	Property string `json:"property,omitempty"`
+}
+
+type lsifToolInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type lsifPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// lsifSymbol is the key for a symbol: the file it's declared in and its name.
+type lsifSymbol struct {
+	file string
+	name string
+}
+
+type lsifRange struct {
+	document int
+	id       int
+}
+
+type lsifWriter struct {
+	w    *bufio.Writer
+	enc  *json.Encoder
+	id   int
+	err  error
+	root string
+	// columns converts the columns of the files in root to UTF-16.
+	columns *utf16Columns
+}
+
+// LSIF writes an LSIF dump with the definitions and references of every
+// symbol declared in the scope of a file in the analysis, so that code
+// browsers can provide go-to-definition and find-references. The files are
+// read from absRoot since LSIF positions are in UTF-16 code units while Go
+// positions are in bytes.
+func LSIF(w io.Writer, absRoot, version string, a *links.Analysis) error {
+	lw := &lsifWriter{
+		w:       bufio.NewWriter(w),
+		root:    absRoot,
+		columns: newUTF16Columns(os.DirFS(absRoot)),
+	}
+	lw.enc = json.NewEncoder(lw.w)
+
+	lw.emit(lsifElement{
+		Type:             "vertex",
+		Label:            "metaData",
+		Version:          "0.4.3",
+		ProjectRoot:      lw.uri(""),
+		PositionEncoding: "utf-16",
+		ToolInfo:         &lsifToolInfo{Name: "codesee-deps-go", Version: version},
+	})
+	project := lw.emit(lsifElement{Type: "vertex", Label: "project", Kind: "go"})
+
+	documents := map[string]int{}
+	documentIDs := []int{}
+	for _, f := range a.Files {
+		id := lw.emit(lsifElement{Type: "vertex", Label: "document", URI: lw.uri(f.Name), LanguageID: "go"})
+		documents[f.Name] = id
+		documentIDs = append(documentIDs, id)
+	}
+
+	// Every symbol gets a result set that its definition and all of its
+	// references point to.
+	symbols := []lsifSymbol{}
+	definitions := map[lsifSymbol]lsifRange{}
+	resultSets := map[lsifSymbol]int{}
+	references := map[lsifSymbol][]lsifRange{}
+	contains := map[int][]int{}
+
+	for _, f := range a.Files {
+		for _, s := range f.Symbols {
+			if s.Kind == links.SymbolKindMethod {
+				// References are only tracked for identifiers in a package's
+				// scope, which methods aren't part of.
+				continue
+			}
+			symbol := lsifSymbol{file: f.Name, name: s.Name}
+			r := lw.emitRange(f.Name, s.Line, s.Column, s.Name)
+			resultSet := lw.emit(lsifElement{Type: "vertex", Label: "resultSet"})
+			lw.emit(lsifElement{Type: "edge", Label: "next", OutV: r, InV: resultSet})
+
+			symbols = append(symbols, symbol)
+			definitions[symbol] = lsifRange{document: documents[f.Name], id: r}
+			resultSets[symbol] = resultSet
+			contains[documents[f.Name]] = append(contains[documents[f.Name]], r)
+		}
+	}
+
+	addReference := func(from, to string, ref links.Reference) {
+		symbol := lsifSymbol{file: to, name: ref.Identifier}
+		resultSet, ok := resultSets[symbol]
+		if !ok {
+			return
+		}
+		r := lw.emitRange(from, ref.Line, ref.Column, ref.Identifier)
+		lw.emit(lsifElement{Type: "edge", Label: "next", OutV: r, InV: resultSet})
+		references[symbol] = append(references[symbol], lsifRange{document: documents[from], id: r})
+		contains[documents[from]] = append(contains[documents[from]], r)
+	}
+	for _, f := range a.Files {
+		for _, ref := range f.LocalReferences {
+			addReference(f.Name, f.Name, ref)
+		}
+	}
+	for _, e := range a.Edges {
+		for _, ref := range e.References {
+			addReference(e.From, e.To, ref)
+		}
+	}
+
+	for _, id := range documentIDs {
+		if len(contains[id]) > 0 {
+			lw.emit(lsifElement{Type: "edge", Label: "contains", OutV: id, InVs: contains[id]})
+		}
+	}
+
+	for _, symbol := range symbols {
+		def := definitions[symbol]
+
+		definitionResult := lw.emit(lsifElement{Type: "vertex", Label: "definitionResult"})
+		lw.emit(lsifElement{Type: "edge", Label: "textDocument/definition", OutV: resultSets[symbol], InV: definitionResult})
+		lw.emit(lsifElement{Type: "edge", Label: "item", OutV: definitionResult, InVs: []int{def.id}, Document: def.document})
+
+		referenceResult := lw.emit(lsifElement{Type: "vertex", Label: "referenceResult"})
+		lw.emit(lsifElement{Type: "edge", Label: "textDocument/references", OutV: resultSets[symbol], InV: referenceResult})
+		lw.emit(lsifElement{Type: "edge", Label: "item", OutV: referenceResult, InVs: []int{def.id}, Document: def.document, Property: "definitions"})
+
+		// Item edges are per document, so the references need to be grouped
+		// by the document they're in.
+		byDocument := map[int][]int{}
+		order := []int{}
+		for _, ref := range references[symbol] {
+			if _, ok := byDocument[ref.document]; !ok {
+				order = append(order, ref.document)
+			}
+			byDocument[ref.document] = append(byDocument[ref.document], ref.id)
+		}
+		for _, document := range order {
+			lw.emit(lsifElement{Type: "edge", Label: "item", OutV: referenceResult, InVs: byDocument[document], Document: document, Property: "references"})
+		}
+	}
+
+	lw.emit(lsifElement{Type: "edge", Label: "contains", OutV: project, InVs: documentIDs})
+
+	if lw.err != nil {
+		return lw.err
+	}
+	return errors.WithStack(lw.w.Flush())
+}
+
+// emit assigns the next ID to the element and writes it. Once there's an error,
+// nothing else is written and the error is kept so it can be returned at the
+// end.
+func (lw *lsifWriter) emit(e lsifElement) int {
+	lw.id++
+	e.ID = lw.id
+	if lw.err == nil {
+		lw.err = errors.WithStack(lw.enc.Encode(e))
+	}
+	return e.ID
+}
+
+// emitRange writes a range vertex for the identifier at the given 1-based line
+// and byte column in the file.
+func (lw *lsifWriter) emitRange(filename string, line, column int, identifier string) int {
+	start := lw.character(filename, line, column)
+	return lw.emit(lsifElement{
+		Type:  "vertex",
+		Label: "range",
+		Start: &lsifPosition{Line: line - 1, Character: start},
+		End:   &lsifPosition{Line: line - 1, Character: start + len(utf16.Encode([]rune(identifier)))},
+	})
+}
+
+// character converts a 1-based byte column to a 0-based UTF-16 offset.
+func (lw *lsifWriter) character(filename string, line, column int) int {
+	character, err := lw.columns.offset(filename, line, column)
+	if err != nil && lw.err == nil {
+		lw.err = err
+	}
+	return character
+}
+
+func (lw *lsifWriter) uri(filename string) string {
+	return "file://" + filepath.ToSlash(filepath.Join(lw.root, filename))
+}
@@ -0,0 +1,74 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLSIF(t *testing.T) {
+	t.Run("links references to their definitions", func(tt *testing.T) {
+		root, err := filepath.Abs("../testdata/simple-repo")
+		require.NoError(tt, err)
+		a, err := links.Analyze(root)
+		require.NoError(tt, err)
+
+		var buf bytes.Buffer
+		err = LSIF(&buf, root, "dev", a)
+		require.NoError(tt, err)
+
+		elements := map[int]lsifElement{}
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var e lsifElement
+			err := dec.Decode(&e)
+			require.NoError(tt, err)
+			elements[e.ID] = e
+		}
+
+		// Find the range for the SetupSignals definition, and follow it to
+		// its references.
+		var definition lsifElement
+		for _, e := range elements {
+			if e.Label == "range" && e.Start.Line == 11 && e.Start.Character == 5 && e.End.Character == 17 {
+				definition = e
+			}
+		}
+		require.NotZero(tt, definition.ID)
+
+		resultSet := 0
+		for _, e := range elements {
+			if e.Label == "next" && e.OutV == definition.ID {
+				resultSet = e.InV
+			}
+		}
+		require.NotZero(tt, resultSet)
+
+		referenceResult := 0
+		for _, e := range elements {
+			if e.Label == "textDocument/references" && e.OutV == resultSet {
+				referenceResult = e.InV
+			}
+		}
+		require.NotZero(tt, referenceResult)
+
+		uris := []string{}
+		for _, e := range elements {
+			if e.Label == "item" && e.OutV == referenceResult && e.Property == "references" {
+				uris = append(uris, elements[e.Document].URI)
+				for _, id := range e.InVs {
+					assert.Equal(tt, "range", elements[id].Label)
+				}
+			}
+		}
+		assert.ElementsMatch(tt, []string{
+			"file://" + root + "/cmd/api/main.go",
+			"file://" + root + "/pkg/signals/signals_test.go",
+		}, uris)
+	})
+}
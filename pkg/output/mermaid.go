@@ -0,0 +1,44 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/pkg/errors"
+)
+
+// Mermaid writes the graph as a Mermaid flowchart, which GitHub renders
+// natively when it's put in a ```mermaid code block.
+func Mermaid(w io.Writer, g *graph.Graph) error {
+	var b strings.Builder
+
+	b.WriteString("flowchart LR\n")
+
+	// Node IDs in Mermaid can't contain most punctuation, so every node gets a
+	// generated ID and the path is used as its label instead.
+	ids := make(map[string]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[node.ID] = id
+		fmt.Fprintf(&b, "    %s[\"%s\"]\n", id, mermaidEscape(node.ID))
+	}
+
+	for _, e := range g.Edges {
+		if e.Weight > 1 {
+			fmt.Fprintf(&b, "    %s -->|%d| %s\n", ids[e.From], e.Weight, ids[e.To])
+		} else {
+			fmt.Fprintf(&b, "    %s --> %s\n", ids[e.From], ids[e.To])
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return errors.WithStack(err)
+}
+
+// mermaidEscape escapes the characters that would end a quoted Mermaid label
+// early.
+func mermaidEscape(s string) string {
+	return strings.Replace(s, "\"", "#quot;", -1)
+}
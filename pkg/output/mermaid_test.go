@@ -0,0 +1,45 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMermaid(t *testing.T) {
+	t.Run("renders a flowchart", func(tt *testing.T) {
+		g := graph.New([]links.Link{
+			{From: "cmd/api/main.go", To: "pkg/server/server.go"},
+			{From: "pkg/server/server.go", To: "pkg/handlers/handlers.go"},
+		})
+
+		var buf bytes.Buffer
+		err := Mermaid(&buf, g)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, `flowchart LR
+    n0["cmd/api/main.go"]
+    n1["pkg/handlers/handlers.go"]
+    n2["pkg/server/server.go"]
+    n0 --> n2
+    n2 --> n1
+`, buf.String())
+	})
+
+	t.Run("labels aggregated edges with their weight", func(tt *testing.T) {
+		g := &graph.Graph{
+			Nodes: []graph.Node{{ID: "pkg/a"}, {ID: "pkg/b"}},
+			Edges: []graph.Edge{{From: "pkg/a", To: "pkg/b", Weight: 3}},
+		}
+
+		var buf bytes.Buffer
+		err := Mermaid(&buf, g)
+		require.NoError(tt, err)
+
+		assert.Contains(tt, buf.String(), "n0 -->|3| n1\n")
+	})
+}
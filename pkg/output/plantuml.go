@@ -0,0 +1,116 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/pkg/errors"
+)
+
+type PlantUMLOptions struct {
+	// Diagram is either "component" for a component diagram or "package" for
+	// a package diagram.
+	Diagram string
+	// GroupDepth is the number of leading directories that the packages are
+	// grouped by. For example, with a depth of 1, pkg/server and pkg/handlers
+	// are both grouped in pkg. A depth of 0 doesn't group anything.
+	GroupDepth int
+}
+
+// PlantUML writes a package graph as a PlantUML diagram, with the packages
+// grouped by their leading directories. The external edges go from a package to
+// an external module, and are drawn as dashed arrows to a cloud for every
+// module. Only the external edges from packages in the graph are included.
+func PlantUML(w io.Writer, g *graph.Graph, external []graph.Edge, opts PlantUMLOptions) error {
+	if opts.Diagram != "component" && opts.Diagram != "package" {
+		return errors.Errorf("unknown PlantUML diagram %q", opts.Diagram)
+	}
+
+	var b strings.Builder
+	b.WriteString("@startuml\n")
+
+	ids := make(map[string]string, len(g.Nodes))
+	groups := map[string][]graph.Node{}
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+		group := groupOf(n.ID, opts.GroupDepth)
+		groups[group] = append(groups[group], n)
+	}
+
+	groupNames := make([]string, 0, len(groups))
+	for group := range groups {
+		groupNames = append(groupNames, group)
+	}
+	sort.Strings(groupNames)
+
+	for _, group := range groupNames {
+		indent := ""
+		if group != "" {
+			fmt.Fprintf(&b, "package %s {\n", plantUMLString(group))
+			indent = "  "
+		}
+		for _, n := range groups[group] {
+			label := n.ID
+			if group != "" {
+				label = strings.TrimPrefix(n.ID, group+"/")
+			}
+			if opts.Diagram == "component" {
+				fmt.Fprintf(&b, "%scomponent %s as %s\n", indent, plantUMLString(label), ids[n.ID])
+			} else {
+				fmt.Fprintf(&b, "%spackage %s as %s {\n%s}\n", indent, plantUMLString(label), ids[n.ID], indent)
+			}
+		}
+		if group != "" {
+			b.WriteString("}\n")
+		}
+	}
+
+	externalIDs := map[string]string{}
+	for _, e := range external {
+		if _, ok := ids[e.From]; !ok {
+			continue
+		}
+		if _, ok := externalIDs[e.To]; ok {
+			continue
+		}
+		id := fmt.Sprintf("x%d", len(externalIDs))
+		externalIDs[e.To] = id
+		if opts.Diagram == "component" {
+			fmt.Fprintf(&b, "cloud %s as %s\n", plantUMLString(e.To), id)
+		} else {
+			fmt.Fprintf(&b, "package %s as %s <<Cloud>> {\n}\n", plantUMLString(e.To), id)
+		}
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "%s --> %s : %d\n", ids[e.From], ids[e.To], e.Weight)
+	}
+	for _, e := range external {
+		if _, ok := ids[e.From]; !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "%s ..> %s\n", ids[e.From], externalIDs[e.To])
+	}
+
+	b.WriteString("@enduml\n")
+
+	_, err := io.WriteString(w, b.String())
+	return errors.WithStack(err)
+}
+
+// groupOf returns the first depth directories of a package directory, or an
+// empty string if the package isn't nested deep enough to be grouped.
+func groupOf(dir string, depth int) string {
+	segments := strings.Split(dir, "/")
+	if depth <= 0 || len(segments) <= depth {
+		return ""
+	}
+	return strings.Join(segments[:depth], "/")
+}
+
+func plantUMLString(s string) string {
+	return "\"" + strings.Replace(s, "\"", "'", -1) + "\""
+}
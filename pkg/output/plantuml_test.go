@@ -0,0 +1,64 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlantUML(t *testing.T) {
+	g := &graph.Graph{
+		Nodes: []graph.Node{{ID: "cmd/api"}, {ID: "pkg/server"}, {ID: "tools"}},
+		Edges: []graph.Edge{{From: "cmd/api", To: "pkg/server", Weight: 2}},
+	}
+	external := []graph.Edge{
+		{From: "pkg/server", To: "github.com/pkg/errors", Weight: 1},
+		{From: "other", To: "golang.org/x/mod", Weight: 1},
+	}
+
+	t.Run("writes a component diagram grouped by directory", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := PlantUML(&buf, g, external, PlantUMLOptions{Diagram: "component", GroupDepth: 1})
+		require.NoError(tt, err)
+
+		assert.Equal(tt, `@startuml
+component "tools" as n2
+package "cmd" {
+  component "api" as n0
+}
+package "pkg" {
+  component "server" as n1
+}
+cloud "github.com/pkg/errors" as x0
+n0 --> n1 : 2
+n1 ..> x0
+@enduml
+`, buf.String())
+	})
+
+	t.Run("writes a package diagram", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := PlantUML(&buf, g, nil, PlantUMLOptions{Diagram: "package"})
+		require.NoError(tt, err)
+
+		assert.Equal(tt, `@startuml
+package "cmd/api" as n0 {
+}
+package "pkg/server" as n1 {
+}
+package "tools" as n2 {
+}
+n0 --> n1 : 2
+@enduml
+`, buf.String())
+	})
+
+	t.Run("returns an error for an unknown diagram", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := PlantUML(&buf, g, nil, PlantUMLOptions{Diagram: "class"})
+		assert.EqualError(tt, err, `unknown PlantUML diagram "class"`)
+	})
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"io"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/pkg/errors"
+)
+
+//go:embed report.html
+var reportHTML string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportHTML))
+
+type reportData struct {
+	Files  []reportFile `json:"files"`
+	Edges  []reportEdge `json:"edges"`
+	Cycles [][]string   `json:"cycles"`
+}
+
+type reportFile struct {
+	ID      string `json:"id"`
+	Package string `json:"package"`
+	Test    bool   `json:"test"`
+	Lines   int    `json:"lines"`
+}
+
+type reportEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Weight int    `json:"weight"`
+}
+
+// Report writes a self-contained HTML page with an interactive viewer for the
+// file graph. Everything the page needs is embedded in it, so it works
+// offline.
+func Report(w io.Writer, title string, g *graph.Graph) error {
+	data := reportData{
+		Files:  make([]reportFile, 0, len(g.Nodes)),
+		Edges:  make([]reportEdge, 0, len(g.Edges)),
+		Cycles: [][]string{},
+	}
+	for _, n := range g.Nodes {
+		data.Files = append(data.Files, reportFile{
+			ID:      n.ID,
+			Package: n.Package,
+			Test:    n.Test,
+			Lines:   n.Lines,
+		})
+	}
+	for _, e := range g.Edges {
+		data.Edges = append(data.Edges, reportEdge{From: e.From, To: e.To, Weight: e.Weight})
+	}
+	for _, component := range g.StronglyConnectedComponents() {
+		if len(component) > 1 {
+			data.Cycles = append(data.Cycles, component)
+		}
+	}
+
+	// json.Marshal escapes <, > and &, so the data is safe to put in a script
+	// tag as is.
+	js, err := json.Marshal(data)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = reportTemplate.Execute(w, struct {
+		Title string
+		Data  template.JS
+	}{
+		Title: title,
+		Data:  template.JS(js),
+	})
+	return errors.WithStack(err)
+}
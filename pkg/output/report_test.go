@@ -0,0 +1,30 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	t.Run("embeds the graph and its cycles in the page without breaking out of the script tag", func(tt *testing.T) {
+		g := graph.New([]links.Link{
+			{From: "pkg/a/a.go", To: "pkg/a/b.go"},
+			{From: "pkg/a/b.go", To: "pkg/a/a.go"},
+			{From: "pkg/c/<c>.go", To: "pkg/a/a.go"},
+		})
+
+		var buf bytes.Buffer
+		err := Report(&buf, "<repo>", g)
+		require.NoError(tt, err)
+
+		html := buf.String()
+		assert.Contains(tt, html, "<title>&lt;repo&gt; - codesee-deps-go</title>")
+		assert.Contains(tt, html, `"cycles":[["pkg/a/a.go","pkg/a/b.go"]]`)
+		assert.Contains(tt, html, `"id":"pkg/c/\u003cc\u003e.go"`)
+	})
+}
@@ -0,0 +1,196 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/Codesee-io/codesee-deps-go/pkg/rules"
+	"github.com/pkg/errors"
+)
+
+// syntaxErrorRuleID is the ID of the SARIF rule for diagnostics, since they
+// aren't caused by any of the rules in the rules file.
+const syntaxErrorRuleID = "syntax-error"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               int                   `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// SARIF writes the rule violations and the diagnostics of an analysis as a
+// SARIF 2.1.0 log, which code review tools can show inline. Every violation is
+// located at the first reference that causes it, with the rest of the
+// references as related locations. The files are read from fsys, which should
+// be the root of the analysis, since SARIF columns are in UTF-16 code units
+// while Go columns are in bytes.
+func SARIF(w io.Writer, fsys fs.FS, version string, rs []rules.Rule, violations []rules.Violation, diagnostics []links.Diagnostic) error {
+	columns := newUTF16Columns(fsys)
+	var err error
+	location := func(filename string, line, column int, identifier string) sarifLocation {
+		start, columnErr := columns.offset(filename, line, column)
+		if columnErr != nil && err == nil {
+			err = columnErr
+		}
+		region := sarifRegion{StartLine: line, StartColumn: start + 1}
+		if identifier != "" {
+			region.EndLine = line
+			region.EndColumn = start + 1 + len(utf16.Encode([]rune(identifier)))
+		}
+		return sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filename, URIBaseID: "%SRCROOT%"},
+				Region:           region,
+			},
+		}
+	}
+
+	driver := sarifDriver{
+		Name:           "codesee-deps-go",
+		Version:        version,
+		InformationURI: "https://github.com/Codesee-io/codesee-deps-go",
+		Rules: []sarifRule{{
+			ID:               syntaxErrorRuleID,
+			ShortDescription: sarifMessage{Text: "The file couldn't be parsed, so its package was left out of the analysis."},
+		}},
+	}
+	for _, rule := range rs {
+		// The rule text is used as the ID rather than its line, so that
+		// results stay the same when the rules file is reordered.
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:               rule.String(),
+			ShortDescription: sarifMessage{Text: rule.String()},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, d := range diagnostics {
+		results = append(results, sarifResult{
+			RuleID:    syntaxErrorRuleID,
+			Level:     "error",
+			Message:   sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{location(d.File, d.Line, d.Column, "")},
+		})
+	}
+	for _, v := range violations {
+		result := sarifResult{
+			RuleID:  v.Rule.String(),
+			Level:   "error",
+			Message: sarifMessage{Text: violationMessage(v)},
+		}
+		if len(v.References) == 0 {
+			result.Locations = []sarifLocation{location(v.From, 1, 1, "")}
+		}
+		for i, ref := range v.References {
+			l := location(v.From, ref.Line, ref.Column, ref.Identifier)
+			if i == 0 {
+				result.Locations = []sarifLocation{l}
+				continue
+			}
+			l.ID = i
+			l.Message = &sarifMessage{Text: ref.Identifier}
+			result.RelatedLocations = append(result.RelatedLocations, l)
+		}
+		results = append(results, result)
+	}
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:       sarifTool{Driver: driver},
+			ColumnKind: "utf16CodeUnits",
+			Results:    results,
+		}},
+	}, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", out)
+	return errors.WithStack(err)
+}
+
+// violationMessage describes a violation along with the identifiers that cause
+// it, e.g. pkg/domain/user.go depends on pkg/db/db.go through Open, which
+// breaks "pkg/domain/** must not depend on pkg/db/**".
+func violationMessage(v rules.Violation) string {
+	identifiers := []string{}
+	seen := map[string]bool{}
+	for _, ref := range v.References {
+		if !seen[ref.Identifier] {
+			seen[ref.Identifier] = true
+			identifiers = append(identifiers, ref.Identifier)
+		}
+	}
+
+	through := ""
+	if len(identifiers) > 0 {
+		through = " through " + strings.Join(identifiers, ", ")
+	}
+	return fmt.Sprintf("%s depends on %s%s, which breaks %q", v.From, v.To, through, v.Rule.String())
+}
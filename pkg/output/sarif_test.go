@@ -0,0 +1,91 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/Codesee-io/codesee-deps-go/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSARIF(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pkg/domain/user.go":   {Data: []byte("package domain\n\nvar _ = \"é\"; var _ = db.Open()\nvar _ = db.Close\n")},
+		"pkg/broken/broken.go": {Data: []byte("package broken\n\nnot Go\n")},
+	}
+	rule := rules.Rule{Files: "pkg/domain/**", Kind: rules.KindMustNotDependOn, Targets: []string{"pkg/db/**"}, Line: 2}
+	violations := []rules.Violation{{
+		Rule: rule,
+		From: "pkg/domain/user.go",
+		To:   "pkg/db/db.go",
+		References: []links.Reference{
+			{Identifier: "Open", Line: 3, Column: 26},
+			{Identifier: "Close", Line: 4, Column: 12},
+		},
+	}}
+	diagnostics := []links.Diagnostic{
+		{File: "pkg/broken/broken.go", Line: 3, Column: 1, Message: "expected declaration, found not"},
+	}
+
+	t.Run("writes the rules and results of a run", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := SARIF(&buf, fsys, "dev", []rules.Rule{rule}, violations, diagnostics)
+		require.NoError(tt, err)
+
+		var log sarifLog
+		err = json.Unmarshal(buf.Bytes(), &log)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, "2.1.0", log.Version)
+		require.Len(tt, log.Runs, 1)
+		run := log.Runs[0]
+		assert.Equal(tt, "codesee-deps-go", run.Tool.Driver.Name)
+		assert.Equal(tt, "dev", run.Tool.Driver.Version)
+		assert.Equal(tt, []sarifRule{
+			{ID: "syntax-error", ShortDescription: sarifMessage{Text: "The file couldn't be parsed, so its package was left out of the analysis."}},
+			{ID: "pkg/domain/** must not depend on pkg/db/**", ShortDescription: sarifMessage{Text: "pkg/domain/** must not depend on pkg/db/**"}},
+		}, run.Tool.Driver.Rules)
+
+		require.Len(tt, run.Results, 2)
+		assert.Equal(tt, sarifResult{
+			RuleID:  "syntax-error",
+			Level:   "error",
+			Message: sarifMessage{Text: "expected declaration, found not"},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: "pkg/broken/broken.go", URIBaseID: "%SRCROOT%"},
+					Region:           sarifRegion{StartLine: 3, StartColumn: 1},
+				},
+			}},
+		}, run.Results[0])
+
+		violation := run.Results[1]
+		assert.Equal(tt, "pkg/domain/** must not depend on pkg/db/**", violation.RuleID)
+		assert.Equal(tt, `pkg/domain/user.go depends on pkg/db/db.go through Open, Close, which breaks "pkg/domain/** must not depend on pkg/db/**"`, violation.Message.Text)
+		require.Len(tt, violation.Locations, 1)
+		// The é is two bytes but a single UTF-16 code unit.
+		assert.Equal(tt, sarifRegion{StartLine: 3, StartColumn: 25, EndLine: 3, EndColumn: 29}, violation.Locations[0].PhysicalLocation.Region)
+		require.Len(tt, violation.RelatedLocations, 1)
+		assert.Equal(tt, 1, violation.RelatedLocations[0].ID)
+		assert.Equal(tt, &sarifMessage{Text: "Close"}, violation.RelatedLocations[0].Message)
+		assert.Equal(tt, sarifRegion{StartLine: 4, StartColumn: 12, EndLine: 4, EndColumn: 17}, violation.RelatedLocations[0].PhysicalLocation.Region)
+	})
+
+	t.Run("writes an empty list of results without any problems", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := SARIF(&buf, fsys, "dev", nil, nil, nil)
+		require.NoError(tt, err)
+
+		assert.Contains(tt, buf.String(), `"results": []`)
+	})
+
+	t.Run("returns an error when a file can't be read", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := SARIF(&buf, fstest.MapFS{}, "dev", []rules.Rule{rule}, violations, nil)
+		assert.Error(tt, err)
+	})
+}
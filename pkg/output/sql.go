@@ -0,0 +1,79 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/pkg/errors"
+)
+
+// sqlBatchSize is the maximum number of rows in a single INSERT statement.
+const sqlBatchSize = 100
+
+const sqlSchema = `CREATE TABLE nodes (
+  id TEXT PRIMARY KEY,
+  package TEXT NOT NULL,
+  test BOOLEAN NOT NULL,
+  lines INTEGER NOT NULL
+);
+
+CREATE TABLE links (
+  source TEXT NOT NULL REFERENCES nodes (id),
+  target TEXT NOT NULL REFERENCES nodes (id),
+  weight INTEGER NOT NULL,
+  kind TEXT NOT NULL,
+  PRIMARY KEY (source, target)
+);
+`
+
+// SQL writes the graph as a SQL dump with a nodes table and a links table. It
+// sticks to standard SQL so that it can be loaded into most databases and
+// warehouses as is.
+func SQL(w io.Writer, g *graph.Graph) error {
+	var b strings.Builder
+
+	b.WriteString("BEGIN;\n\n")
+	b.WriteString(sqlSchema)
+
+	nodes := make([]string, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		nodes = append(nodes, fmt.Sprintf("(%s, %s, %s, %d)", sqlString(n.ID), sqlString(n.Package), sqlBool(n.Test), n.Lines))
+	}
+	writeInserts(&b, "nodes (id, package, test, lines)", nodes)
+
+	edges := make([]string, 0, len(g.Edges))
+	for _, e := range g.Edges {
+		edges = append(edges, fmt.Sprintf("(%s, %s, %d, %s)", sqlString(e.From), sqlString(e.To), e.Weight, sqlString(string(e.Kind))))
+	}
+	writeInserts(&b, "links (source, target, weight, kind)", edges)
+
+	b.WriteString("\nCOMMIT;\n")
+
+	_, err := io.WriteString(w, b.String())
+	return errors.WithStack(err)
+}
+
+// writeInserts writes INSERT statements for the rows, batching them so that a
+// single statement doesn't get too big.
+func writeInserts(b *strings.Builder, table string, rows []string) {
+	for start := 0; start < len(rows); start += sqlBatchSize {
+		end := start + sqlBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		fmt.Fprintf(b, "\nINSERT INTO %s VALUES\n  %s;\n", table, strings.Join(rows[start:end], ",\n  "))
+	}
+}
+
+func sqlString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+func sqlBool(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
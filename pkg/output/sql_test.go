@@ -0,0 +1,39 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQL(t *testing.T) {
+	t.Run("writes the schema and inserts for the nodes and links", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := SQL(&buf, testGraph)
+		require.NoError(tt, err)
+
+		sql := buf.String()
+		assert.Contains(tt, sql, "CREATE TABLE nodes (")
+		assert.Contains(tt, sql, "CREATE TABLE links (")
+		assert.Contains(tt, sql, "INSERT INTO nodes (id, package, test, lines) VALUES\n"+
+			"  ('cmd/api/main.go', 'simple-repo/cmd/api', FALSE, 40),\n"+
+			"  ('pkg/server/server.go', 'simple-repo/pkg/server', FALSE, 17),\n"+
+			"  ('pkg/server/server_test.go', 'simple-repo/pkg/server', TRUE, 12);\n")
+		assert.Contains(tt, sql, "INSERT INTO links (source, target, weight, kind) VALUES\n"+
+			"  ('cmd/api/main.go', 'pkg/server/server.go', 2, 'import'),\n"+
+			"  ('pkg/server/server_test.go', 'pkg/server/server.go', 1, 'package');\n")
+	})
+
+	t.Run("escapes quotes in strings", func(tt *testing.T) {
+		g := &graph.Graph{Nodes: []graph.Node{{ID: "it's.go"}}}
+
+		var buf bytes.Buffer
+		err := SQL(&buf, g)
+		require.NoError(tt, err)
+
+		assert.Contains(tt, buf.String(), "('it''s.go', '', FALSE, 0)")
+	})
+}
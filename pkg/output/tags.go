@@ -0,0 +1,111 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/pkg/errors"
+)
+
+// ctagsKinds maps symbol kinds to the kind letters that universal-ctags uses
+// for Go. Methods are functions with a type scope, like universal-ctags does.
+var ctagsKinds = map[links.SymbolKind]string{
+	links.SymbolKindFunc:      "f",
+	links.SymbolKindMethod:    "f",
+	links.SymbolKindStruct:    "s",
+	links.SymbolKindInterface: "i",
+	links.SymbolKindType:      "t",
+	links.SymbolKindConst:     "c",
+	links.SymbolKindVar:       "v",
+}
+
+type ctagsEntry struct {
+	file   string
+	symbol links.Symbol
+}
+
+// CTags writes a universal-ctags compatible tags file for all the symbols in
+// the analysis. Every filename is prefixed with prefix, which should be the
+// path from wherever the tags file is written to the root directory. The tags
+// are sorted by name, so editors can use a binary search on them.
+func CTags(w io.Writer, a *links.Analysis, prefix, version string) error {
+	entries := []ctagsEntry{}
+	for _, f := range a.Files {
+		for _, s := range f.Symbols {
+			entries = append(entries, ctagsEntry{file: filepath.ToSlash(filepath.Join(prefix, f.Name)), symbol: s})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].symbol.Name != entries[j].symbol.Name {
+			return entries[i].symbol.Name < entries[j].symbol.Name
+		}
+		if entries[i].file != entries[j].file {
+			return entries[i].file < entries[j].file
+		}
+		return entries[i].symbol.Line < entries[j].symbol.Line
+	})
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "!_TAG_FILE_FORMAT\t2\t/extended format; --format=1 will not append ;\" to lines/\n")
+	fmt.Fprintf(bw, "!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/\n")
+	fmt.Fprintf(bw, "!_TAG_PROGRAM_NAME\tcodesee-deps-go\t//\n")
+	fmt.Fprintf(bw, "!_TAG_PROGRAM_VERSION\t%s\t//\n", version)
+
+	for _, e := range entries {
+		fmt.Fprintf(bw, "%s\t%s\t%d;\"\t%s\tline:%d", e.symbol.Name, e.file, e.symbol.Line, ctagsKinds[e.symbol.Kind], e.symbol.Line)
+		if e.symbol.Receiver != "" {
+			fmt.Fprintf(bw, "\ttype:%s", e.symbol.Receiver)
+		}
+		bw.WriteString("\n")
+	}
+
+	return errors.WithStack(bw.Flush())
+}
+
+// ETags writes an Emacs TAGS file for all the symbols in the analysis. Unlike
+// ctags, every tag includes the text of the line it's on, so the files are read
+// from absRoot. Every filename is prefixed with prefix, like for CTags.
+func ETags(w io.Writer, a *links.Analysis, absRoot, prefix string) error {
+	bw := bufio.NewWriter(w)
+
+	for _, f := range a.Files {
+		if len(f.Symbols) == 0 {
+			continue
+		}
+
+		src, err := ioutil.ReadFile(filepath.Join(absRoot, f.Name))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		lines := strings.SplitAfter(string(src), "\n")
+
+		// The byte offset of the start of every line.
+		offsets := make([]int, len(lines))
+		for i := 1; i < len(lines); i++ {
+			offsets[i] = offsets[i-1] + len(lines[i-1])
+		}
+
+		var section strings.Builder
+		for _, s := range f.Symbols {
+			if s.Line < 1 || s.Line > len(lines) {
+				continue
+			}
+			line := strings.TrimRight(lines[s.Line-1], "\r\n")
+			end := s.Column - 1 + len(s.Name)
+			if end > len(line) {
+				end = len(line)
+			}
+			fmt.Fprintf(&section, "%s\x7f%s\x01%d,%d\n", line[:end], s.Name, s.Line, offsets[s.Line-1])
+		}
+
+		fmt.Fprintf(bw, "\x0c\n%s,%d\n%s", filepath.ToSlash(filepath.Join(prefix, f.Name)), section.Len(), section.String())
+	}
+
+	return errors.WithStack(bw.Flush())
+}
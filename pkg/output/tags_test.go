@@ -0,0 +1,63 @@
+package output
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCTags(t *testing.T) {
+	t.Run("writes sorted tags with their kinds", func(tt *testing.T) {
+		a := &links.Analysis{
+			Files: []links.File{
+				{
+					Name: "pkg/parser/parser.go",
+					Symbols: []links.Symbol{
+						{Name: "Parser", Kind: links.SymbolKindStruct, Line: 25, Column: 6},
+						{Name: "New", Kind: links.SymbolKindFunc, Line: 30, Column: 6},
+						{Name: "Parse", Kind: links.SymbolKindMethod, Receiver: "Parser", Line: 37, Column: 19},
+					},
+				},
+			},
+		}
+
+		var buf bytes.Buffer
+		err := CTags(&buf, a, "..", "dev")
+		require.NoError(tt, err)
+
+		assert.Equal(tt, "!_TAG_FILE_FORMAT\t2\t/extended format; --format=1 will not append ;\" to lines/\n"+
+			"!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/\n"+
+			"!_TAG_PROGRAM_NAME\tcodesee-deps-go\t//\n"+
+			"!_TAG_PROGRAM_VERSION\tdev\t//\n"+
+			"New\t../pkg/parser/parser.go\t30;\"\tf\tline:30\n"+
+			"Parse\t../pkg/parser/parser.go\t37;\"\tf\tline:37\ttype:Parser\n"+
+			"Parser\t../pkg/parser/parser.go\t25;\"\ts\tline:25\n", buf.String())
+	})
+}
+
+func TestETags(t *testing.T) {
+	t.Run("writes a section per file with the line text", func(tt *testing.T) {
+		root, err := filepath.Abs("../testdata/simple-repo")
+		require.NoError(tt, err)
+		a := &links.Analysis{
+			Files: []links.File{
+				{
+					Name: "pkg/server/server.go",
+					Symbols: []links.Symbol{
+						{Name: "New", Kind: links.SymbolKindFunc, Line: 10, Column: 6},
+					},
+				},
+			},
+		}
+
+		var buf bytes.Buffer
+		err = ETags(&buf, a, root, "")
+		require.NoError(tt, err)
+
+		assert.Equal(tt, "\x0c\npkg/server/server.go,19\nfunc New\x7fNew\x0110,78\n", buf.String())
+	})
+}
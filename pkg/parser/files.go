@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// files reads the files under root, either from disk or from an fs.FS. The
+// filenames that are passed in always start with root, so the rest of the
+// parser doesn't need to know where the files actually come from.
+type files struct {
+	root string
+	// fsys is the file system that root is read from. It's nil when the files
+	// are read from disk.
+	fsys fs.FS
+}
+
+// fsPath converts a filename under root into a path in fsys.
+func (f files) fsPath(filename string) (string, error) {
+	rel, err := filepath.Rel(f.root, filename)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	rel = filepath.ToSlash(rel)
+	if !fs.ValidPath(rel) {
+		return "", errors.Errorf("%s isn't in %s", filename, f.root)
+	}
+	return rel, nil
+}
+
+func (f files) readFile(filename string) ([]byte, error) {
+	if f.fsys == nil {
+		content, err := ioutil.ReadFile(filename)
+		return content, errors.WithStack(err)
+	}
+
+	name, err := f.fsPath(filename)
+	if err != nil {
+		return nil, err
+	}
+	content, err := fs.ReadFile(f.fsys, name)
+	return content, errors.WithStack(err)
+}
+
+// exists returns whether a file exists.
+func (f files) exists(filename string) (bool, error) {
+	var err error
+	if f.fsys == nil {
+		_, err = os.Stat(filename)
+	} else {
+		var name string
+		name, err = f.fsPath(filename)
+		if err != nil {
+			return false, err
+		}
+		_, err = fs.Stat(f.fsys, name)
+	}
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (f files) readDir(dir string) ([]fs.DirEntry, error) {
+	if f.fsys == nil {
+		entries, err := os.ReadDir(dir)
+		return entries, errors.WithStack(err)
+	}
+
+	name, err := f.fsPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := fs.ReadDir(f.fsys, name)
+	return entries, errors.WithStack(err)
+}
+
+// parseDir parses all the Go files in a directory like parser.ParseDir does.
+// The filenames in the FileSet start with root, even when they're read from an
+// fs.FS. Unlike parser.ParseDir, the syntax errors of every file are returned
+// instead of only the ones from the first file with errors, as a
+// scanner.ErrorList.
+func (f files) parseDir(fset *token.FileSet, dir string) (map[string]*ast.Package, error) {
+	entries, err := f.readDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs := map[string]*ast.Package{}
+	syntaxErrors := scanner.ErrorList{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+
+		filename := filepath.Join(dir, entry.Name())
+		src, err := f.readFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(fset, filename, src, 0)
+		if list, ok := err.(scanner.ErrorList); ok {
+			syntaxErrors = append(syntaxErrors, list...)
+			continue
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		pkg, ok := pkgs[file.Name.Name]
+		if !ok {
+			pkg = &ast.Package{Name: file.Name.Name, Files: map[string]*ast.File{}}
+			pkgs[file.Name.Name] = pkg
+		}
+		pkg.Files[filename] = file
+	}
+
+	if len(syntaxErrors) > 0 {
+		return pkgs, syntaxErrors
+	}
+	return pkgs, nil
+}
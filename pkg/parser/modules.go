@@ -1,36 +1,34 @@
 package parser
 
 import (
-	"io/ioutil"
-	"os"
 	"path/filepath"
 
 	"github.com/pkg/errors"
 	"golang.org/x/mod/modfile"
 )
 
-// recursiveModulePath takes in the root of the project and a directory within
-// that root, and it will search all directories starting with dir and ending
-// with root to find a go.mod file. It returns the module path (which is
+// recursiveModulePath takes in the files of the project and a directory within
+// their root, and it will search all directories starting with dir and ending
+// with the root to find a go.mod file. It returns the module path (which is
 // retrieved from the go.mod), and the directory where the go.mod was found,
 // which is the module root.
-func recursiveModulePath(root, dir string) (string, string, error) {
+func recursiveModulePath(f files, dir string) (string, string, error) {
 	modFilePath := dir + "/go.mod"
-	_, err := os.Stat(modFilePath)
-	if err != nil && !os.IsNotExist(err) {
+	exists, err := f.exists(modFilePath)
+	if err != nil {
 		return "", "", errors.WithStack(err)
 	}
 
-	if err == nil {
+	if exists {
 		// A go.mod file exists in this directory.
-		mod, err := ioutil.ReadFile(modFilePath)
+		mod, err := f.readFile(modFilePath)
 		if err != nil {
 			return "", "", errors.WithStack(err)
 		}
 		return modfile.ModulePath(mod), dir, nil
 	}
 
-	if dir == root {
+	if dir == f.root {
 		// This means that we didn't find a go.mod file anywhere in the
 		// directory tree, so this project might not be using Go modules.
 		// Behavior without a go.mod is not fully tested. We could either throw
@@ -41,5 +39,31 @@ func recursiveModulePath(root, dir string) (string, string, error) {
 
 	// If we didn't find a go.mod in this directory, and we're not at the root
 	// yet, go up one directory and look for a go.mod file there.
-	return recursiveModulePath(root, filepath.Dir(dir))
+	return recursiveModulePath(f, filepath.Dir(dir))
+}
+
+// moduleRequires returns the paths of all the modules that are required in the
+// go.mod file in moduleRoot. If there isn't a module root, then there aren't
+// any requirements either.
+func moduleRequires(f files, moduleRoot string) ([]string, error) {
+	if moduleRoot == "" {
+		return []string{}, nil
+	}
+
+	modFilePath := moduleRoot + "/go.mod"
+	mod, err := f.readFile(modFilePath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	modFile, err := modfile.ParseLax(modFilePath, mod, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	requires := make([]string, 0, len(modFile.Require))
+	for _, r := range modFile.Require {
+		requires = append(requires, r.Mod.Path)
+	}
+	return requires, nil
 }
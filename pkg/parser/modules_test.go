@@ -12,7 +12,7 @@ func TestRecursiveModulePath(t *testing.T) {
 		root := "../testdata/simple-repo"
 		dir := "../testdata/simple-repo"
 
-		modulePath, moduleRoot, err := recursiveModulePath(root, dir)
+		modulePath, moduleRoot, err := recursiveModulePath(files{root: root}, dir)
 		require.NoError(tt, err)
 
 		assert.Equal(tt, "simple-repo", modulePath)
@@ -23,7 +23,7 @@ func TestRecursiveModulePath(t *testing.T) {
 		root := "../testdata/simple-repo"
 		dir := "../testdata/simple-repo/cmd/api"
 
-		modulePath, moduleRoot, err := recursiveModulePath(root, dir)
+		modulePath, moduleRoot, err := recursiveModulePath(files{root: root}, dir)
 		require.NoError(tt, err)
 
 		assert.Equal(tt, "simple-repo", modulePath)
@@ -34,10 +34,26 @@ func TestRecursiveModulePath(t *testing.T) {
 		root := "../testdata/simple-repo/cmd"
 		dir := "../testdata/simple-repo/cmd/api"
 
-		modulePath, moduleRoot, err := recursiveModulePath(root, dir)
+		modulePath, moduleRoot, err := recursiveModulePath(files{root: root}, dir)
 		require.NoError(tt, err)
 
 		assert.Equal(tt, "", modulePath)
 		assert.Equal(tt, "", moduleRoot)
 	})
 }
+
+func TestModuleRequires(t *testing.T) {
+	t.Run("returns the required modules", func(tt *testing.T) {
+		requires, err := moduleRequires(files{}, "../testdata/simple-repo")
+		require.NoError(tt, err)
+
+		assert.Equal(tt, []string{"github.com/stretchr/testify"}, requires)
+	})
+
+	t.Run("returns an empty list without a module root", func(tt *testing.T) {
+		requires, err := moduleRequires(files{}, "")
+		require.NoError(tt, err)
+
+		assert.Empty(tt, requires)
+	})
+}
@@ -2,8 +2,9 @@ package parser
 
 import (
 	"go/ast"
-	"go/parser"
+	"go/scanner"
 	"go/token"
+	"io/fs"
 
 	"github.com/pkg/errors"
 )
@@ -16,6 +17,10 @@ type ParsedDir struct {
 	// This is used to resolve imports within the same module.
 	ModulePath string
 	ModuleRoot string
+	// Requires is the list of module paths that are required in the go.mod
+	// file. This is used to figure out which module an external import comes
+	// from.
+	Requires []string
 	// Packages is the return value of parser.ParseDir, where the map key is the
 	// package name and the map value is the AST of the whole package (which is
 	// a directory in Go).
@@ -23,14 +28,30 @@ type ParsedDir struct {
 }
 
 type Parser struct {
-	root  string
+	files files
 	cache map[string]*ParsedDir
+	// syntaxErrors is the syntax errors of every directory that couldn't be
+	// parsed.
+	syntaxErrors map[string]scanner.ErrorList
+	// requires caches the required modules by module root, since every
+	// directory in a module shares the same go.mod file.
+	requires map[string][]string
 }
 
 func New(root string) *Parser {
+	return NewFS(root, nil)
+}
+
+// NewFS returns a parser that reads the files from fsys instead of from disk.
+// The root of fsys is treated as if it were the root directory, so the
+// directories that are parsed still need to start with root, and so do the
+// filenames in the ASTs. A nil fsys reads from disk like New.
+func NewFS(root string, fsys fs.FS) *Parser {
 	return &Parser{
-		root:  root,
-		cache: map[string]*ParsedDir{},
+		files:        files{root: root, fsys: fsys},
+		cache:        map[string]*ParsedDir{},
+		syntaxErrors: map[string]scanner.ErrorList{},
+		requires:     map[string][]string{},
 	}
 }
 
@@ -41,16 +62,29 @@ func (p *Parser) Parse(dir string) (*ParsedDir, error) {
 		return parsedDir, nil
 	}
 
-	modulePath, moduleRoot, err := recursiveModulePath(p.root, dir)
+	modulePath, moduleRoot, err := recursiveModulePath(p.files, dir)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
+	requires, ok := p.requires[moduleRoot]
+	if !ok {
+		requires, err = moduleRequires(p.files, moduleRoot)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		p.requires[moduleRoot] = requires
+	}
+
 	fset := token.NewFileSet()
-	pkgs, err := parser.ParseDir(fset, dir, nil, 0)
+	pkgs, err := p.files.parseDir(fset, dir)
 	if err != nil {
 		// If we encounter an error when parsing, then it's probably not a
-		// valid Go file, so we just skip it.
+		// valid Go file, so we just skip it. The syntax errors are kept so
+		// they can be reported.
+		if list, ok := err.(scanner.ErrorList); ok {
+			p.syntaxErrors[dir] = list
+		}
 		p.cache[dir] = nil
 		return nil, nil
 	}
@@ -59,7 +93,22 @@ func (p *Parser) Parse(dir string) (*ParsedDir, error) {
 		FileSet:    fset,
 		ModulePath: modulePath,
 		ModuleRoot: moduleRoot,
+		Requires:   requires,
 		Packages:   pkgs,
 	}
 	return p.cache[dir], nil
 }
+
+// Forget removes a directory from the cache, so its AST can be garbage
+// collected. Parsing the directory again will re-read it from disk.
+func (p *Parser) Forget(dir string) {
+	delete(p.cache, dir)
+	delete(p.syntaxErrors, dir)
+}
+
+// SyntaxErrors returns the syntax errors that caused a directory to be skipped
+// when it was parsed. It's empty for directories that were parsed
+// successfully.
+func (p *Parser) SyntaxErrors(dir string) scanner.ErrorList {
+	return p.syntaxErrors[dir]
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -50,4 +51,80 @@ func TestParser_Parse(t *testing.T) {
 
 		assert.Nil(tt, parsedDir)
 	})
+
+	t.Run("keeps the syntax errors of an invalid directory", func(tt *testing.T) {
+		fsys := fstest.MapFS{
+			"invalid/a.go":     {Data: []byte("package invalid\n\nThis isn't Go\n")},
+			"invalid/b.go":     {Data: []byte("package invalid\n\nfunc (\n")},
+			"invalid/valid.go": {Data: []byte("package invalid\n")},
+		}
+		p := NewFS("/repo", fsys)
+
+		parsedDir, err := p.Parse("/repo/invalid")
+		require.NoError(tt, err)
+		require.Nil(tt, parsedDir)
+
+		syntaxErrors := p.SyntaxErrors("/repo/invalid")
+		filenames := []string{}
+		for _, e := range syntaxErrors {
+			filenames = append(filenames, e.Pos.Filename)
+		}
+		assert.Contains(tt, filenames, "/repo/invalid/a.go")
+		assert.Contains(tt, filenames, "/repo/invalid/b.go")
+		assert.NotContains(tt, filenames, "/repo/invalid/valid.go")
+		assert.Equal(tt, 3, syntaxErrors[0].Pos.Line)
+
+		p.Forget("/repo/invalid")
+		assert.Empty(tt, p.SyntaxErrors("/repo/invalid"))
+	})
+
+	t.Run("parses the directory again after forgetting it", func(tt *testing.T) {
+		root := "../testdata/simple-repo"
+		dir := "../testdata/simple-repo/cmd/api"
+		p := New(root)
+
+		firstParsedDir, err := p.Parse(dir)
+		require.NoError(tt, err)
+		require.NotNil(tt, firstParsedDir)
+
+		p.Forget(dir)
+
+		secondParsedDir, err := p.Parse(dir)
+		require.NoError(tt, err)
+		require.NotNil(tt, secondParsedDir)
+
+		// This asserts that the pointers are different.
+		assert.NotSame(tt, firstParsedDir, secondParsedDir)
+	})
+
+	t.Run("parses a directory from an fs.FS", func(tt *testing.T) {
+		fsys := fstest.MapFS{
+			"go.mod":          {Data: []byte("module example.com/app\n\nrequire github.com/pkg/errors v0.9.1\n")},
+			"cmd/api/main.go": {Data: []byte("package main\n\nfunc main() {}\n")},
+			"cmd/api/README":  {Data: []byte("not Go")},
+		}
+		p := NewFS("/repo", fsys)
+
+		parsedDir, err := p.Parse("/repo/cmd/api")
+		require.NoError(tt, err)
+
+		require.NotNil(tt, parsedDir)
+		assert.Equal(tt, "example.com/app", parsedDir.ModulePath)
+		assert.Equal(tt, "/repo", parsedDir.ModuleRoot)
+		assert.Equal(tt, []string{"github.com/pkg/errors"}, parsedDir.Requires)
+		require.Len(tt, parsedDir.Packages, 1)
+		assert.Contains(tt, parsedDir.Packages["main"].Files, "/repo/cmd/api/main.go")
+	})
+
+	t.Run("returns nil for an invalid file from an fs.FS", func(tt *testing.T) {
+		fsys := fstest.MapFS{
+			"invalid/invalid.go": {Data: []byte("This isn't Go")},
+		}
+		p := NewFS("/repo", fsys)
+
+		parsedDir, err := p.Parse("/repo/invalid")
+		require.NoError(tt, err)
+
+		assert.Nil(tt, parsedDir)
+	})
 }
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Baseline is a snapshot of known violations that are tolerated, so that a
+// check only fails on new ones. Violations are identified by the text of their
+// rule and their link, rather than by the line of the rule, so the rules file
+// can be edited without invalidating the baseline.
+type Baseline struct {
+	Violations []BaselineViolation `json:"violations"`
+}
+
+// BaselineViolation is a single known violation in a baseline.
+type BaselineViolation struct {
+	Rule string `json:"rule"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// NewBaseline returns a baseline with all the violations, sorted by rule and
+// link.
+func NewBaseline(violations []Violation) Baseline {
+	b := Baseline{Violations: []BaselineViolation{}}
+	seen := map[BaselineViolation]bool{}
+	for _, v := range violations {
+		bv := baselineViolation(v)
+		if !seen[bv] {
+			seen[bv] = true
+			b.Violations = append(b.Violations, bv)
+		}
+	}
+
+	sort.Slice(b.Violations, func(i, j int) bool {
+		vi, vj := b.Violations[i], b.Violations[j]
+		if vi.Rule != vj.Rule {
+			return vi.Rule < vj.Rule
+		}
+		if vi.From != vj.From {
+			return vi.From < vj.From
+		}
+		return vi.To < vj.To
+	})
+	return b
+}
+
+// ReadBaseline reads a baseline that was written with Write.
+func ReadBaseline(r io.Reader) (Baseline, error) {
+	var b Baseline
+	err := json.NewDecoder(r).Decode(&b)
+	if err != nil {
+		return Baseline{}, errors.WithStack(err)
+	}
+	if b.Violations == nil {
+		b.Violations = []BaselineViolation{}
+	}
+	return b, nil
+}
+
+// Write writes the baseline as indented JSON, so that it's easy to review
+// changes to it.
+func (b Baseline) Write(w io.Writer) error {
+	out, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = w.Write(append(out, '\n'))
+	return errors.WithStack(err)
+}
+
+// Apply splits the violations into the new ones that aren't in the baseline,
+// and returns them along with a baseline that only has the known violations
+// that still exist. The returned baseline never grows, so fixed violations
+// can't come back without failing the check.
+func (b Baseline) Apply(violations []Violation) ([]Violation, Baseline) {
+	known := map[BaselineViolation]bool{}
+	for _, bv := range b.Violations {
+		known[bv] = true
+	}
+
+	newViolations := []Violation{}
+	remaining := []Violation{}
+	for _, v := range violations {
+		if known[baselineViolation(v)] {
+			remaining = append(remaining, v)
+		} else {
+			newViolations = append(newViolations, v)
+		}
+	}
+
+	return newViolations, NewBaseline(remaining)
+}
+
+func baselineViolation(v Violation) BaselineViolation {
+	return BaselineViolation{Rule: v.Rule.String(), From: v.From, To: v.To}
+}
@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseline(t *testing.T) {
+	rule := Rule{Files: "pkg/domain/**", Kind: KindMustNotDependOn, Targets: []string{"pkg/http/**"}, Line: 3}
+	violations := []Violation{
+		{Rule: rule, From: "pkg/domain/user.go", To: "pkg/http/client.go"},
+		{Rule: rule, From: "pkg/domain/order.go", To: "pkg/http/client.go"},
+	}
+
+	t.Run("writes and reads the violations", func(tt *testing.T) {
+		var buf bytes.Buffer
+		err := NewBaseline(violations).Write(&buf)
+		require.NoError(tt, err)
+
+		b, err := ReadBaseline(&buf)
+		require.NoError(tt, err)
+
+		assert.Equal(tt, Baseline{
+			Violations: []BaselineViolation{
+				{Rule: "pkg/domain/** must not depend on pkg/http/**", From: "pkg/domain/order.go", To: "pkg/http/client.go"},
+				{Rule: "pkg/domain/** must not depend on pkg/http/**", From: "pkg/domain/user.go", To: "pkg/http/client.go"},
+			},
+		}, b)
+	})
+
+	t.Run("only returns the new violations", func(tt *testing.T) {
+		b := NewBaseline(violations[:1])
+
+		// The rule moved to another line, which shouldn't matter.
+		moved := rule
+		moved.Line = 10
+		newViolations, _ := b.Apply([]Violation{
+			{Rule: moved, From: "pkg/domain/user.go", To: "pkg/http/client.go"},
+			{Rule: moved, From: "pkg/domain/order.go", To: "pkg/http/client.go"},
+		})
+
+		assert.Equal(tt, []Violation{
+			{Rule: moved, From: "pkg/domain/order.go", To: "pkg/http/client.go"},
+		}, newViolations)
+	})
+
+	t.Run("shrinks when violations are fixed", func(tt *testing.T) {
+		b := NewBaseline(violations)
+
+		newViolations, remaining := b.Apply(violations[1:])
+
+		assert.Empty(tt, newViolations)
+		assert.Equal(tt, NewBaseline(violations[1:]), remaining)
+	})
+}
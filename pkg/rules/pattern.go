@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"path"
+	"strings"
+)
+
+// matches returns whether a file matches a pattern. A file matches if the
+// pattern matches its path or the path of any of the directories it's in, so
+// pkg/http and cmd/* match every file under them. In patterns, * matches
+// anything except a /, and ** matches any number of directories.
+func matches(pattern, filename string) bool {
+	patternSegments := strings.Split(pattern, "/")
+	for name := filename; name != "." && name != "/"; name = path.Dir(name) {
+		if matchSegments(patternSegments, strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchedPath returns the shortest directory that a file matches a pattern
+// at. For example, cmd/* matches cmd/api/main.go at cmd/api, and pkg/domain/**
+// matches pkg/domain/user/user.go at pkg/domain. When only the file itself
+// matches, e.g. cmd/* and cmd/main.go, it's the directory the file is in, so
+// the files of a package are always in the same match.
+func matchedPath(pattern, filename string) (string, bool) {
+	patternSegments := strings.Split(pattern, "/")
+	segments := strings.Split(filename, "/")
+	for i := 1; i < len(segments); i++ {
+		if matchSegments(patternSegments, segments[:i]) {
+			return strings.Join(segments[:i], "/"), true
+		}
+	}
+	if matchSegments(patternSegments, segments) {
+		return path.Dir(filename), true
+	}
+	return "", false
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	if pattern[0] == "**" {
+		// ** can either match nothing or swallow one more segment of the
+		// name.
+		if matchSegments(pattern[1:], name) {
+			return true
+		}
+		return len(name) > 0 && matchSegments(pattern, name[1:])
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], name[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
+
+// validPattern returns whether a pattern is well formed.
+func validPattern(pattern string) bool {
+	if pattern == "" {
+		return false
+	}
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatches(t *testing.T) {
+	for _, tc := range []struct {
+		pattern  string
+		filename string
+		expected bool
+	}{
+		{"pkg/domain/**", "pkg/domain/user.go", true},
+		{"pkg/domain/**", "pkg/domain/user/user.go", true},
+		{"pkg/domain/**", "pkg/domainx/user.go", false},
+		{"pkg/http", "pkg/http/server.go", true},
+		{"pkg/http", "pkg/httputil/server.go", false},
+		{"cmd/*", "cmd/api/main.go", true},
+		{"cmd/*", "cmd/main.go", true},
+		{"cmd/*", "pkg/cmd/main.go", false},
+		{"**/internal/**", "pkg/a/internal/b/b.go", true},
+		{"**/*_test.go", "pkg/a/a_test.go", true},
+		{"**/*_test.go", "pkg/a/a.go", false},
+		{"pkg/*/db.go", "pkg/users/db.go", true},
+	} {
+		t.Run(tc.pattern+" "+tc.filename, func(tt *testing.T) {
+			assert.Equal(tt, tc.expected, matches(tc.pattern, tc.filename))
+		})
+	}
+}
+
+func TestMatchedPath(t *testing.T) {
+	for _, tc := range []struct {
+		pattern  string
+		filename string
+		expected string
+	}{
+		{"cmd/*", "cmd/api/main.go", "cmd/api"},
+		{"cmd/*", "cmd/main.go", "cmd"},
+		{"**/*_test.go", "pkg/a/a_test.go", "pkg/a"},
+		{"pkg/domain/**", "pkg/domain/user/user.go", "pkg/domain"},
+		{"pkg/http", "pkg/http/server.go", "pkg/http"},
+		{"**/internal/**", "pkg/a/internal/b/b.go", "pkg/a/internal"},
+		{"pkg/http", "pkg/log/log.go", ""},
+	} {
+		t.Run(tc.pattern+" "+tc.filename, func(tt *testing.T) {
+			matched, ok := matchedPath(tc.pattern, tc.filename)
+			assert.Equal(tt, tc.expected != "", ok)
+			assert.Equal(tt, tc.expected, matched)
+		})
+	}
+}
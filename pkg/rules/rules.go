@@ -0,0 +1,185 @@
+// Package rules checks the links of a project against architecture rules, like
+// which directories are allowed to depend on each other.
+package rules
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/pkg/errors"
+)
+
+// Kind is the kind of constraint that a rule puts on its files.
+type Kind string
+
+const (
+	// KindMustNotDependOn forbids the files from depending on any of the
+	// targets.
+	KindMustNotDependOn Kind = "must not depend on"
+	// KindMayOnlyDependOn forbids the files from depending on anything except
+	// the targets and the files in the same match of the pattern.
+	KindMayOnlyDependOn Kind = "may only depend on"
+	// KindMayOnlyBeDependedOnBy forbids anything except the targets and the
+	// files in the same match of the pattern from depending on the files.
+	KindMayOnlyBeDependedOnBy Kind = "may only be depended on by"
+)
+
+// kinds is every kind, longest first, so that a rule is never parsed as a kind
+// that's a prefix of its actual kind.
+var kinds = []Kind{KindMayOnlyBeDependedOnBy, KindMayOnlyDependOn, KindMustNotDependOn}
+
+// Rule is a single line of a rules file, e.g.
+//
+//	pkg/domain/** must not depend on pkg/http/**, pkg/db/**
+type Rule struct {
+	// Files is the pattern of the files that the rule applies to.
+	Files string `json:"files"`
+	Kind  Kind   `json:"kind"`
+	// Targets is the patterns of the files on the other side of the links. It's
+	// empty when the rule ends with "nothing".
+	Targets []string `json:"targets"`
+	// Line is the line of the rule in the rules file.
+	Line int `json:"line"`
+}
+
+func (r Rule) String() string {
+	targets := "nothing"
+	if len(r.Targets) > 0 {
+		targets = strings.Join(r.Targets, ", ")
+	}
+	return fmt.Sprintf("%s %s %s", r.Files, r.Kind, targets)
+}
+
+// Parse reads a rules file with one rule per line. Every rule is a pattern for
+// the files it applies to, followed by its kind, followed by a comma-separated
+// list of patterns or the word nothing. Empty lines and lines that start with
+// # are ignored.
+func Parse(r io.Reader) ([]Rule, error) {
+	rules := []Rule{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		rule, err := parseRule(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+		rule.Line = line
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return rules, nil
+}
+
+func parseRule(text string) (Rule, error) {
+	for _, kind := range kinds {
+		i := strings.Index(text, " "+string(kind)+" ")
+		if i < 0 {
+			continue
+		}
+
+		rule := Rule{
+			Files:   strings.TrimSpace(text[:i]),
+			Kind:    kind,
+			Targets: []string{},
+		}
+		if !validPattern(rule.Files) {
+			return Rule{}, errors.Errorf("invalid pattern %q", rule.Files)
+		}
+
+		targets := strings.TrimSpace(text[i+len(kind)+2:])
+		if targets == "nothing" {
+			return rule, nil
+		}
+		for _, target := range strings.Split(targets, ",") {
+			target = strings.TrimSpace(target)
+			if !validPattern(target) {
+				return Rule{}, errors.Errorf("invalid pattern %q", target)
+			}
+			rule.Targets = append(rule.Targets, target)
+		}
+		return rule, nil
+	}
+
+	return Rule{}, errors.Errorf("%q doesn't contain %q, %q or %q", text, KindMustNotDependOn, KindMayOnlyDependOn, KindMayOnlyBeDependedOnBy)
+}
+
+// Violation is a link that breaks a rule.
+type Violation struct {
+	Rule Rule   `json:"rule"`
+	From string `json:"from"`
+	To   string `json:"to"`
+	// References is every use of an identifier that causes the link.
+	References []links.Reference `json:"references"`
+}
+
+// Check returns every link in the analysis that breaks any of the rules,
+// sorted by the line of the rule and then by the link. A link that breaks
+// several rules is included once for every rule.
+func Check(a *links.Analysis, rules []Rule) []Violation {
+	violations := []Violation{}
+	for _, rule := range rules {
+		for _, e := range a.Edges {
+			if rule.breaks(e.From, e.To) {
+				violations = append(violations, Violation{
+					Rule:       rule,
+					From:       e.From,
+					To:         e.To,
+					References: e.References,
+				})
+			}
+		}
+	}
+
+	// The edges are already sorted, so only the rules need sorting.
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Rule.Line < violations[j].Rule.Line
+	})
+	return violations
+}
+
+// breaks returns whether a link from one file to another breaks the rule.
+func (r Rule) breaks(from, to string) bool {
+	switch r.Kind {
+	case KindMustNotDependOn:
+		return matches(r.Files, from) && matchesAny(r.Targets, to)
+	case KindMayOnlyDependOn:
+		return matches(r.Files, from) && !r.internal(from, to) && !matchesAny(r.Targets, to)
+	case KindMayOnlyBeDependedOnBy:
+		return matches(r.Files, to) && !r.internal(from, to) && !matchesAny(r.Targets, from)
+	}
+	return false
+}
+
+// internal returns whether a link stays within the same directory that the
+// rule's pattern matches, e.g. from cmd/api/main.go to cmd/api/server.go for
+// cmd/*. These links are always allowed, but a link from cmd/api to cmd/worker
+// isn't, since those are different matches of the pattern.
+func (r Rule) internal(from, to string) bool {
+	fromPath, ok := matchedPath(r.Files, from)
+	if !ok {
+		return false
+	}
+	toPath, ok := matchedPath(r.Files, to)
+	return ok && fromPath == toPath
+}
+
+func matchesAny(patterns []string, filename string) bool {
+	for _, pattern := range patterns {
+		if matches(pattern, filename) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Codesee-io/codesee-deps-go/pkg/links"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("parses every kind of rule", func(tt *testing.T) {
+		rules, err := Parse(strings.NewReader(`# The domain is the core of the hexagon.
+pkg/domain/** must not depend on pkg/http/**, pkg/db/**
+
+pkg/http/** may only depend on pkg/domain/**
+cmd/* may only be depended on by nothing
+`))
+		require.NoError(tt, err)
+
+		assert.Equal(tt, []Rule{
+			{Files: "pkg/domain/**", Kind: KindMustNotDependOn, Targets: []string{"pkg/http/**", "pkg/db/**"}, Line: 2},
+			{Files: "pkg/http/**", Kind: KindMayOnlyDependOn, Targets: []string{"pkg/domain/**"}, Line: 4},
+			{Files: "cmd/*", Kind: KindMayOnlyBeDependedOnBy, Targets: []string{}, Line: 5},
+		}, rules)
+		assert.Equal(tt, "cmd/* may only be depended on by nothing", rules[2].String())
+	})
+
+	t.Run("returns an error with the line of an invalid rule", func(tt *testing.T) {
+		_, err := Parse(strings.NewReader("\npkg/a should not depend on pkg/b\n"))
+		assert.EqualError(tt, err, `line 2: "pkg/a should not depend on pkg/b" doesn't contain "must not depend on", "may only depend on" or "may only be depended on by"`)
+	})
+
+	t.Run("returns an error for an invalid pattern", func(tt *testing.T) {
+		_, err := Parse(strings.NewReader("pkg/a must not depend on pkg/[b"))
+		assert.EqualError(tt, err, `line 1: invalid pattern "pkg/[b"`)
+	})
+}
+
+func TestCheck(t *testing.T) {
+	a := &links.Analysis{
+		Edges: []links.Edge{
+			{From: "cmd/api/main.go", To: "pkg/http/server.go", References: []links.Reference{{Identifier: "New", Line: 10, Column: 7}}},
+			{From: "pkg/domain/user.go", To: "pkg/domain/id.go"},
+			{From: "pkg/domain/user.go", To: "pkg/http/client.go", References: []links.Reference{{Identifier: "Client", Line: 3, Column: 9}}},
+			{From: "pkg/http/server.go", To: "cmd/api/flags.go"},
+			{From: "pkg/http/server.go", To: "pkg/domain/user.go"},
+			{From: "pkg/http/server.go", To: "pkg/http/routes.go"},
+			{From: "pkg/http/server.go", To: "pkg/log/log.go"},
+		},
+	}
+
+	t.Run("returns the links that break a rule", func(tt *testing.T) {
+		mustNot := Rule{Files: "pkg/domain/**", Kind: KindMustNotDependOn, Targets: []string{"pkg/http/**"}, Line: 1}
+		mayOnly := Rule{Files: "pkg/http/**", Kind: KindMayOnlyDependOn, Targets: []string{"pkg/domain/**"}, Line: 2}
+		nothing := Rule{Files: "cmd/*", Kind: KindMayOnlyBeDependedOnBy, Targets: []string{}, Line: 3}
+
+		violations := Check(a, []Rule{nothing, mayOnly, mustNot})
+
+		assert.Equal(tt, []Violation{
+			{Rule: mustNot, From: "pkg/domain/user.go", To: "pkg/http/client.go", References: []links.Reference{{Identifier: "Client", Line: 3, Column: 9}}},
+			{Rule: mayOnly, From: "pkg/http/server.go", To: "cmd/api/flags.go"},
+			{Rule: mayOnly, From: "pkg/http/server.go", To: "pkg/log/log.go"},
+			{Rule: nothing, From: "pkg/http/server.go", To: "cmd/api/flags.go"},
+		}, violations)
+	})
+
+	t.Run("only allows links within the same match of the pattern", func(tt *testing.T) {
+		a := &links.Analysis{
+			Edges: []links.Edge{
+				{From: "cmd/a/main.go", To: "cmd/a/flags.go"},
+				{From: "cmd/a/main.go", To: "cmd/b/main.go"},
+				{From: "pkg/http/server.go", To: "pkg/http/middleware/auth.go"},
+			},
+		}
+		nothing := Rule{Files: "cmd/*", Kind: KindMayOnlyBeDependedOnBy, Targets: []string{}, Line: 1}
+		mayOnly := Rule{Files: "pkg/http/**", Kind: KindMayOnlyDependOn, Targets: []string{}, Line: 2}
+
+		violations := Check(a, []Rule{nothing, mayOnly})
+
+		assert.Equal(tt, []Violation{
+			{Rule: nothing, From: "cmd/a/main.go", To: "cmd/b/main.go"},
+		}, violations)
+	})
+
+	t.Run("allows links between the files of the same package", func(tt *testing.T) {
+		a := &links.Analysis{
+			Edges: []links.Edge{
+				{From: "cmd/main.go", To: "cmd/run.go"},
+			},
+		}
+		nothing := Rule{Files: "cmd/*", Kind: KindMayOnlyBeDependedOnBy, Targets: []string{}, Line: 1}
+
+		violations := Check(a, []Rule{nothing})
+
+		assert.Empty(tt, violations)
+	})
+
+	t.Run("returns an empty list without violations", func(tt *testing.T) {
+		violations := Check(a, []Rule{{Files: "pkg/log", Kind: KindMustNotDependOn, Targets: []string{"pkg/**"}}})
+
+		assert.Empty(tt, violations)
+	})
+}